@@ -120,5 +120,26 @@ type RequestFailure interface {
 // NewRequestFailure returns a new request error wrapper for the given Error
 // provided.
 func NewRequestFailure(err Error, statusCode int, requestID string) RequestFailure {
-	return newRequestError(err, statusCode, requestID)
+	return newRequestError(err, statusCode, requestID, "")
+}
+
+// An ExtendedRequestFailure is a RequestFailure with the extended request ID
+// returned by the service, identifying the host which handled the request.
+// Both IDs should be included when contacting support about a failed request.
+//
+//     if reqErr, ok := err.(awserr.ExtendedRequestFailure); ok {
+//         fmt.Println(reqErr.RequestID(), reqErr.HostID())
+//     }
+type ExtendedRequestFailure interface {
+	RequestFailure
+
+	// The extended request ID, or host ID, returned by the service for a
+	// request failure. This will be empty if the service returned none.
+	HostID() string
+}
+
+// NewExtendedRequestFailure returns a new request error wrapper for the given
+// Error provided, with the extended request ID of the request.
+func NewExtendedRequestFailure(err Error, statusCode int, requestID, hostID string) ExtendedRequestFailure {
+	return newRequestError(err, statusCode, requestID, hostID)
 }
@@ -122,21 +122,23 @@ type requestError struct {
 	awsError
 	statusCode int
 	requestID  string
+	hostID     string
 }
 
 // newRequestError returns a wrapped error with additional information for
-// request status code, and service requestID.
+// request status code, and service requestID and hostID.
 //
 // Should be used to wrap all request which involve service requests. Even if
 // the request failed without a service response, but had an HTTP status code
 // that may be meaningful.
 //
 // Also wraps original errors via the baseError.
-func newRequestError(err Error, statusCode int, requestID string) *requestError {
+func newRequestError(err Error, statusCode int, requestID, hostID string) *requestError {
 	return &requestError{
 		awsError:   err,
 		statusCode: statusCode,
 		requestID:  requestID,
+		hostID:     hostID,
 	}
 }
 
@@ -144,6 +146,9 @@ func newRequestError(err Error, statusCode int, requestID string) *requestError
 // Satisfies the error interface.
 func (r requestError) Error() string {
 	extra := fmt.Sprintf("status code: %d, request id: %s", r.statusCode, r.requestID)
+	if r.hostID != "" {
+		extra += ", host id: " + r.hostID
+	}
 
 	return SprintError(r.Code(), r.Message(), extra, r.OrigErr())
 }
@@ -164,6 +169,11 @@ func (r requestError) RequestID() string {
 	return r.requestID
 }
 
+// HostID returns the wrapped extended request ID
+func (r requestError) HostID() string {
+	return r.hostID
+}
+
 // OrigErrs returns the original errors if one was set. An empty slice is
 // returned if no error was set.
 func (r requestError) OrigErrs() []error {
@@ -0,0 +1,483 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/client"
+	"github.com/golib/aws/service/client/metadata"
+	"github.com/golib/aws/service/credentials"
+	"github.com/golib/aws/service/defaults"
+	"github.com/golib/aws/service/signer/v4"
+)
+
+// Error codes the Server responds with.
+const (
+	// UnknownOperationErrCode is the error code of the response to requests
+	// which match no route of the Server.
+	UnknownOperationErrCode = "UnknownOperationException"
+
+	// SignatureDoesNotMatchErrCode is the error code of the response to
+	// requests whose signature the Server failed to verify.
+	SignatureDoesNotMatchErrCode = "SignatureDoesNotMatch"
+
+	// ThrottlingErrCode is the error code of ThrottleResponse.
+	ThrottlingErrCode = "ThrottlingException"
+
+	// InternalFailureErrCode is the error code of ServerErrorResponse.
+	InternalFailureErrCode = "InternalFailure"
+)
+
+// A Fault is a failure the Server injects instead of responding.
+type Fault int
+
+// Faults the Server can inject.
+const (
+	// FaultNone responds normally.
+	FaultNone Fault = iota
+
+	// FaultConnectionReset resets the connection without responding.
+	FaultConnectionReset
+)
+
+// A Response is a scripted response of a Route.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// Latency is the duration the Server waits before responding, or
+	// injecting the Fault.
+	Latency time.Duration
+
+	// Fault is a failure injected instead of responding.
+	Fault Fault
+}
+
+// JSONResponse returns a Response with the status code and JSON body.
+func JSONResponse(statusCode int, body string) Response {
+	return Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       []byte(body),
+	}
+}
+
+// ErrorResponse returns a Response with the status code and a JSON error
+// payload with the error code and message.
+func ErrorResponse(statusCode int, code, message string) Response {
+	b, _ := json.Marshal(map[string]string{
+		"__type":  code,
+		"message": message,
+	})
+
+	return JSONResponse(statusCode, string(b))
+}
+
+// ThrottleResponse returns a Response throttling the request.
+func ThrottleResponse() Response {
+	return ErrorResponse(http.StatusBadRequest, ThrottlingErrCode, "Rate exceeded.")
+}
+
+// ServerErrorResponse returns a Response failing the request with an
+// internal server error.
+func ServerErrorResponse() Response {
+	return ErrorResponse(http.StatusInternalServerError, InternalFailureErrCode, "An internal error occurred.")
+}
+
+// ConnectionResetResponse returns a Response resetting the connection.
+func ConnectionResetResponse() Response {
+	return Response{Fault: FaultConnectionReset}
+}
+
+// WithLatency returns a copy of the Response delayed by the latency.
+func (r Response) WithLatency(latency time.Duration) Response {
+	r.Latency = latency
+	return r
+}
+
+// WithHeader returns a copy of the Response with the header set.
+func (r Response) WithHeader(key, value string) Response {
+	header := http.Header{}
+	for k, v := range r.Header {
+		header[k] = append([]string{}, v...)
+	}
+	header.Set(key, value)
+
+	r.Header = header
+	return r
+}
+
+// A Route matches requests of an operation, and returns its scripted
+// responses in order.
+type Route struct {
+	operation string
+	method    string
+	path      string
+
+	mu        sync.Mutex
+	responses []Response
+	calls     int
+}
+
+// Respond appends responses the route returns, in order, to successive
+// requests. Once all have been returned the last is repeated.
+func (r *Route) Respond(responses ...Response) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.responses = append(r.responses, responses...)
+	return r
+}
+
+// Calls returns the number of requests the route matched.
+func (r *Route) Calls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.calls
+}
+
+// next returns the response to the route's next request.
+func (r *Route) next() Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls++
+	if len(r.responses) == 0 {
+		return JSONResponse(http.StatusOK, "{}")
+	}
+
+	i := r.calls - 1
+	if i >= len(r.responses) {
+		i = len(r.responses) - 1
+	}
+	return r.responses[i]
+}
+
+// A ReceivedRequest is a request received by the Server.
+type ReceivedRequest struct {
+	Operation string
+	Method    string
+	URL       *url.URL
+	Header    http.Header
+	Body      []byte
+
+	// SignatureError is the error verifying the request's signature, if the
+	// Server verifies signatures.
+	SignatureError error
+}
+
+// A Server is a programmable fake service. Requests are routed by their
+// operation, taken from the X-Aws-Target header or Action parameter, or by
+// their method and path. Each route returns scripted responses, which can
+// inject latency and faults.
+//
+//	server := mock.NewServer()
+//	defer server.Close()
+//
+//	server.On("GetItem").Respond(
+//		mock.ThrottleResponse(),
+//		mock.JSONResponse(200, `{"Item":{}}`),
+//	)
+//
+//	svc := server.NewClient()
+type Server struct {
+	*httptest.Server
+
+	// Credentials requests are verified to be signed with, if set. Requests
+	// with an invalid signature fail with SignatureDoesNotMatchErrCode.
+	Credentials *credentials.Value
+
+	mu       sync.Mutex
+	routes   []*Route
+	requests []ReceivedRequest
+}
+
+// NewServer returns a new, started, Server. The Server must be closed once
+// the test completes.
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// NewClient returns a client sending signed requests to the Server, with the
+// credentials the Server verifies if set.
+func (s *Server) NewClient(cfgs ...*service.Config) *client.Client {
+	creds := credentials.NewStaticCredentials("AKID", "SECRET", "SESSION")
+	if s.Credentials != nil {
+		creds = credentials.NewStaticCredentialsFromCreds(*s.Credentials)
+	}
+
+	def := defaults.Get()
+	def.Config.MergeIn(service.NewConfig().
+		WithCredentials(creds).
+		WithRegion("mock-region"))
+	def.Config.MergeIn(cfgs...)
+
+	svc := client.New(*def.Config, metadata.ClientInfo{
+		ServiceName: "Mock",
+		Endpoint:    s.URL,
+		APIVersion:  "2015-12-08",
+	}, def.Handlers)
+	svc.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+
+	return svc
+}
+
+// On returns the route of requests for the operation, adding it if needed.
+func (s *Server) On(operation string) *Route {
+	return s.route(&Route{operation: operation})
+}
+
+// OnPath returns the route of requests with the method and path, adding it
+// if needed.
+func (s *Server) OnPath(method, path string) *Route {
+	return s.route(&Route{method: method, path: path})
+}
+
+func (s *Server) route(route *Route) *Route {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.routes {
+		if r.operation == route.operation && r.method == route.method && r.path == route.path {
+			return r
+		}
+	}
+
+	s.routes = append(s.routes, route)
+	return route
+}
+
+// Requests returns the requests received by the Server, in order.
+func (s *Server) Requests() []ReceivedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]ReceivedRequest{}, s.requests...)
+}
+
+// RequestsFor returns the requests of the operation received by the Server.
+func (s *Server) RequestsFor(operation string) []ReceivedRequest {
+	var reqs []ReceivedRequest
+	for _, r := range s.Requests() {
+		if r.Operation == operation {
+			reqs = append(reqs, r)
+		}
+	}
+
+	return reqs
+}
+
+// Reset removes the Server's routes and received requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.routes = nil
+	s.requests = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+
+	received := ReceivedRequest{
+		Operation: requestOperation(r, body),
+		Method:    r.Method,
+		URL:       r.URL,
+		Header:    r.Header,
+		Body:      body,
+	}
+	if s.Credentials != nil {
+		received.SignatureError = verifySignature(r, body, *s.Credentials)
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, received)
+	route := s.match(received)
+	s.mu.Unlock()
+
+	if received.SignatureError != nil {
+		writeResponse(w, ErrorResponse(http.StatusForbidden, SignatureDoesNotMatchErrCode,
+			received.SignatureError.Error()))
+		return
+	}
+
+	if route == nil {
+		writeResponse(w, ErrorResponse(http.StatusNotFound, UnknownOperationErrCode,
+			fmt.Sprintf("no route for %s %s", r.Method, r.URL.Path)))
+		return
+	}
+
+	resp := route.next()
+	if resp.Latency > 0 {
+		select {
+		case <-time.After(resp.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch resp.Fault {
+	case FaultConnectionReset:
+		resetConnection(w)
+	default:
+		writeResponse(w, resp)
+	}
+}
+
+// match returns the route of the request, or nil. Must be called with the
+// lock held.
+func (s *Server) match(r ReceivedRequest) *Route {
+	for _, route := range s.routes {
+		if route.operation != "" {
+			if route.operation == r.Operation {
+				return route
+			}
+			continue
+		}
+
+		if route.method == r.Method && route.path == r.URL.Path {
+			return route
+		}
+	}
+
+	return nil
+}
+
+// requestOperation returns the operation of the request from its
+// X-Aws-Target header, or Action query or form parameter.
+func requestOperation(r *http.Request, body []byte) string {
+	if target := r.Header.Get("X-Aws-Target"); target != "" {
+		return target[strings.LastIndex(target, ".")+1:]
+	}
+
+	if action := r.URL.Query().Get("Action"); action != "" {
+		return action
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			return form.Get("Action")
+		}
+	}
+
+	return ""
+}
+
+func writeResponse(w http.ResponseWriter, resp Response) {
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	w.Write(resp.Body)
+}
+
+// resetConnection closes the request's connection without responding,
+// resetting it if possible.
+func resetConnection(w http.ResponseWriter) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		panic("mock: connection reset not supported by the server")
+	}
+
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
+
+// verifySignature verifies the request's SigV4 signature was computed with
+// the credentials.
+func verifySignature(r *http.Request, body []byte, creds credentials.Value) error {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 ") {
+		return fmt.Errorf("request not signed")
+	}
+
+	var credential, signedHeaders, signature string
+	for _, part := range strings.Split(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "Credential":
+			credential = kv[1]
+		case "SignedHeaders":
+			signedHeaders = kv[1]
+		case "Signature":
+			signature = kv[1]
+		}
+	}
+
+	// Credential is AKID/date/region/service/aws4_request.
+	scope := strings.Split(credential, "/")
+	if len(scope) != 5 {
+		return fmt.Errorf("invalid credential scope %q", credential)
+	}
+	if scope[0] != creds.AccessKeyID {
+		return fmt.Errorf("unknown access key %s", scope[0])
+	}
+
+	signTime, err := time.Parse("20060102T150405Z", r.Header.Get("X-Aws-Date"))
+	if err != nil {
+		return fmt.Errorf("invalid X-Aws-Date, %v", err)
+	}
+
+	// Sign a request with only the headers the client signed.
+	req, err := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	if err != nil {
+		return err
+	}
+	for _, k := range strings.Split(signedHeaders, ";") {
+		if k == "host" {
+			continue
+		}
+		if k == "content-length" {
+			req.Header.Set("Content-Length", fmt.Sprintf("%d", r.ContentLength))
+			continue
+		}
+		req.Header[http.CanonicalHeaderKey(k)] = r.Header[http.CanonicalHeaderKey(k)]
+	}
+
+	for _, unsignedPayload := range []bool{false, true} {
+		signer := v4.NewSigner(credentials.NewStaticCredentialsFromCreds(creds), func(s *v4.Signer) {
+			s.UnsignedPayload = unsignedPayload
+		})
+
+		signReq := *req
+		signReq.Header = http.Header{}
+		for k, v := range req.Header {
+			signReq.Header[k] = v
+		}
+
+		if _, err := signer.Sign(&signReq, bytes.NewReader(body), scope[3], scope[2], signTime); err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(signReq.Header.Get("Authorization"), "Signature="+signature) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("signature does not match")
+}
@@ -0,0 +1,196 @@
+package mock
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/credentials"
+	"github.com/golib/aws/service/request"
+)
+
+func TestServerRouting(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.On("GetItem").Respond(JSONResponse(200, `{"Item":{}}`))
+	server.On("DescribeRegions").Respond(JSONResponse(200, `{"Regions":[]}`))
+	server.OnPath("GET", "/bucket/key").Respond(JSONResponse(200, `"object"`))
+
+	cases := []struct {
+		Method, Path, Target, Body string
+		StatusCode                 int
+		Expect                     string
+	}{
+		{"POST", "/", "DynamoDB_20120810.GetItem", "{}", 200, `{"Item":{}}`},
+		{"GET", "/?Action=DescribeRegions", "", "", 200, `{"Regions":[]}`},
+		{"POST", "/", "", "Action=DescribeRegions&Version=2016-11-15", 200, `{"Regions":[]}`},
+		{"GET", "/bucket/key", "", "", 200, `"object"`},
+		{"PUT", "/bucket/key", "", "", 404, UnknownOperationErrCode},
+	}
+
+	for i, c := range cases {
+		req, _ := http.NewRequest(c.Method, server.URL+c.Path, strings.NewReader(c.Body))
+		if c.Target != "" {
+			req.Header.Set("X-Aws-Target", c.Target)
+		}
+		if strings.HasPrefix(c.Body, "Action=") {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.Nil(t, err, "case %d", i) {
+			continue
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		assert.Equal(t, c.StatusCode, resp.StatusCode, "case %d", i)
+		assert.Contains(t, string(b), c.Expect, "case %d", i)
+	}
+
+	assert.Equal(t, 1, server.On("GetItem").Calls())
+	assert.Equal(t, 2, server.On("DescribeRegions").Calls())
+	assert.Len(t, server.Requests(), 5)
+
+	reqs := server.RequestsFor("GetItem")
+	if assert.Len(t, reqs, 1) {
+		assert.Equal(t, "POST", reqs[0].Method)
+		assert.Equal(t, "{}", string(reqs[0].Body))
+	}
+
+	server.Reset()
+	assert.Empty(t, server.Requests())
+	assert.Equal(t, 0, server.On("GetItem").Calls())
+}
+
+func TestServerScriptedResponses(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.On("PutItem").Respond(
+		ThrottleResponse(),
+		ServerErrorResponse().WithHeader("X-Aws-Request-Id", "abc"),
+		JSONResponse(200, `{}`),
+	)
+
+	var statusCodes []int
+	for i := 0; i < 4; i++ {
+		req, _ := http.NewRequest("POST", server.URL, nil)
+		req.Header.Set("X-Aws-Target", "DynamoDB_20120810.PutItem")
+
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.Nil(t, err) {
+			return
+		}
+		resp.Body.Close()
+
+		statusCodes = append(statusCodes, resp.StatusCode)
+		if i == 1 {
+			assert.Equal(t, "abc", resp.Header.Get("X-Aws-Request-Id"))
+		}
+	}
+
+	assert.Equal(t, []int{400, 500, 200, 200}, statusCodes)
+}
+
+func TestServerFaults(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.On("Reset").Respond(ConnectionResetResponse())
+	server.On("Slow").Respond(JSONResponse(200, `{}`).WithLatency(50 * time.Millisecond))
+
+	req, _ := http.NewRequest("GET", server.URL+"/?Action=Reset", nil)
+	_, err := http.DefaultClient.Do(req)
+	assert.NotNil(t, err)
+
+	start := time.Now()
+	req, _ = http.NewRequest("GET", server.URL+"/?Action=Slow", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if assert.Nil(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, 200, resp.StatusCode)
+	}
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+}
+
+func TestServerClientRetriesServerErrors(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(
+		ServerErrorResponse(),
+		ConnectionResetResponse(),
+		JSONResponse(200, `{}`),
+	)
+
+	svc := server.NewClient(service.NewConfig().WithMaxRetries(2))
+	svc.Handlers.Build.PushBack(func(r *request.Request) {
+		r.HTTPRequest.Header.Set("X-Aws-Target", "Mock.ListTables")
+	})
+
+	req := svc.NewRequest(&request.Operation{Name: "ListTables", HTTPMethod: "POST", HTTPPath: "/"}, nil, nil)
+	req.Handlers.Retry.PushBack(func(r *request.Request) {
+		r.RetryDelay = 0
+	})
+
+	err := req.Send()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, req.RetryCount)
+	assert.Len(t, server.RequestsFor("ListTables"), 3)
+}
+
+func TestServerVerifySignatures(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.Credentials = &credentials.Value{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}
+	server.On("ListTables").Respond(JSONResponse(200, `{}`))
+
+	cases := []struct {
+		Creds     *credentials.Credentials
+		ExpectErr bool
+	}{
+		{nil, false},
+		{credentials.NewStaticCredentials("AKID", "WRONG", ""), true},
+		{credentials.NewStaticCredentials("OTHER", "SECRET", ""), true},
+	}
+
+	for i, c := range cases {
+		server.Reset()
+		server.On("ListTables").Respond(JSONResponse(200, `{}`))
+
+		cfg := service.NewConfig()
+		if c.Creds != nil {
+			cfg.WithCredentials(c.Creds)
+		}
+
+		svc := server.NewClient(cfg)
+		svc.Handlers.Build.PushBack(func(r *request.Request) {
+			r.HTTPRequest.Header.Set("X-Aws-Target", "Mock.ListTables")
+		})
+
+		req := svc.NewRequest(&request.Operation{Name: "ListTables", HTTPMethod: "POST", HTTPPath: "/"}, nil, nil)
+		req.SetStringBody(`{"Limit":10}`)
+		req.Send()
+
+		reqs := server.Requests()
+		if !assert.Len(t, reqs, 1, "case %d", i) {
+			continue
+		}
+
+		if c.ExpectErr {
+			assert.NotNil(t, reqs[0].SignatureError, "case %d", i)
+			assert.Equal(t, 403, req.HTTPResponse.StatusCode, "case %d", i)
+			assert.Equal(t, 0, server.On("ListTables").Calls(), "case %d", i)
+		} else {
+			assert.Nil(t, reqs[0].SignatureError, "case %d", i)
+			assert.Equal(t, 200, req.HTTPResponse.StatusCode, "case %d", i)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+// Package recorder provides an http.RoundTripper recording the HTTP
+// interactions of service clients to a cassette file, and replaying them, so
+// tests built on client.Client can run without access to the service.
+//
+//	rec, err := recorder.New("testdata/get_item.json", recorder.ModeAuto)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer rec.Stop()
+//
+//	svc := dynamodb.New(sess, service.NewConfig().WithHTTPClient(rec.HTTPClient()))
+//
+// Credentials, and the signature of requests, are redacted from the recorded
+// requests. Requests are replayed by matching their method, path, query and
+// the hash of their body. Headers, and the query parameters of presigned
+// requests which change every time a request is signed, are not matched.
+package recorder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/golib/aws/service/awserr"
+)
+
+const (
+	// InteractionNotFoundErrCode is the error code returned when replaying a
+	// request which was not recorded in the cassette.
+	InteractionNotFoundErrCode = "InteractionNotFound"
+
+	// redacted is the value redacted headers and query parameters are
+	// recorded as.
+	redacted = "REDACTED"
+)
+
+// A Mode is the mode a Recorder handles requests with.
+type Mode int
+
+// Modes of a Recorder.
+const (
+	// ModeReplay replays the interactions of the cassette. Requests which
+	// were not recorded fail.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends requests with the Recorder's Transport, and records
+	// their interactions to the cassette.
+	ModeRecord
+
+	// ModeAuto replays the cassette if it exists, and records it otherwise.
+	ModeAuto
+)
+
+// RedactedHeaders are the request headers which are redacted when recorded.
+var RedactedHeaders = []string{
+	"Authorization",
+	"X-Aws-Security-Token",
+}
+
+// RedactedQuery are the query parameters of presigned requests which are
+// redacted when recorded, and not matched when replayed.
+var RedactedQuery = []string{
+	"X-Aws-Credential",
+	"X-Aws-Security-Token",
+	"X-Aws-Signature",
+	"X-Aws-Date",
+	"X-Aws-Expires",
+}
+
+// A Cassette is the set of recorded HTTP interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// An Interaction is a recorded HTTP request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+
+	replayed bool
+}
+
+// A Request is a recorded HTTP request.
+type Request struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	BodyHash string      `json:"body_hash"`
+}
+
+// A Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// A Recorder is an http.RoundTripper recording or replaying the HTTP
+// interactions of a cassette file. A Recorder is safe to use concurrently.
+type Recorder struct {
+	// Transport is used to send requests when recording. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	mode     Mode
+	path     string
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// New returns a new Recorder of the cassette file at the path. The cassette
+// is loaded if the Recorder replays it. An error is returned if the cassette
+// cannot be loaded.
+func New(path string, mode Mode) (*Recorder, error) {
+	if mode == ModeAuto {
+		mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			mode = ModeReplay
+		}
+	}
+
+	r := &Recorder{
+		mode:     mode,
+		path:     path,
+		cassette: &Cassette{},
+	}
+
+	if mode == ModeReplay {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, r.cassette); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s, %v", path, err)
+		}
+	}
+
+	return r, nil
+}
+
+// Mode returns the mode of the Recorder. A Recorder created with ModeAuto
+// returns the mode it selected.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// HTTPClient returns an http.Client sending requests with the Recorder, to
+// be set as the HTTPClient of a service client's Config.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Stop saves the recorded interactions to the cassette file if recording.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, b, 0644)
+}
+
+// RoundTrip records or replays the HTTP request.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+
+	return r.record(req, body)
+}
+
+// record sends the request and records its interaction.
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	out := *req
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	if len(body) == 0 {
+		out.Body = nil
+	}
+
+	resp, err := transport.RoundTrip(&out)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction := &Interaction{
+		Request: Request{
+			Method:   req.Method,
+			URL:      redactURL(req.URL),
+			Header:   redactHeader(req.Header),
+			Body:     body,
+			BodyHash: bodyHash(body),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     cloneHeader(resp.Header),
+			Body:       respBody,
+		},
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay returns the response of the first interaction matching the request
+// which has not been replayed yet.
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	method, key, hash := req.Method, matchURL(req.URL), bodyHash(body)
+	for _, i := range r.cassette.Interactions {
+		if i.replayed || i.Request.Method != method || i.Request.BodyHash != hash {
+			continue
+		}
+
+		u, err := url.Parse(i.Request.URL)
+		if err != nil || matchURL(u) != key {
+			continue
+		}
+
+		i.replayed = true
+		return i.Response.httpResponse(req), nil
+	}
+
+	return nil, awserr.New(InteractionNotFoundErrCode,
+		fmt.Sprintf("no recorded interaction for %s %s in %s", method, key, r.path), nil)
+}
+
+// httpResponse returns the recorded response as a response to the request.
+func (resp Response) httpResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cloneHeader(resp.Header),
+		Body:          ioutil.NopCloser(bytes.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
+
+// matchURL returns the path and query of the URL requests are matched by,
+// without the query parameters which change when a request is signed.
+func matchURL(u *url.URL) string {
+	query := u.Query()
+	for _, k := range RedactedQuery {
+		query.Del(k)
+	}
+
+	return u.EscapedPath() + "?" + query.Encode()
+}
+
+// redactURL returns the URL with the values of redacted query parameters
+// replaced.
+func redactURL(u *url.URL) string {
+	redactedURL := *u
+	query := redactedURL.Query()
+	for _, k := range RedactedQuery {
+		if _, ok := query[k]; ok {
+			query.Set(k, redacted)
+		}
+	}
+	redactedURL.RawQuery = query.Encode()
+
+	return redactedURL.String()
+}
+
+// redactHeader returns a copy of the header with the values of redacted
+// headers replaced.
+func redactHeader(header http.Header) http.Header {
+	h := cloneHeader(header)
+	for _, k := range RedactedHeaders {
+		if _, ok := h[http.CanonicalHeaderKey(k)]; ok {
+			h.Set(k, redacted)
+		}
+	}
+
+	return h
+}
+
+func cloneHeader(header http.Header) http.Header {
+	h := make(http.Header, len(header))
+	for k, v := range header {
+		h[k] = append([]string{}, v...)
+	}
+
+	return h
+}
+
+func bodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
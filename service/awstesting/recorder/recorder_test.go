@@ -0,0 +1,141 @@
+package recorder_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/awstesting/recorder"
+	"github.com/golib/aws/service/client"
+	"github.com/golib/aws/service/credentials"
+	"github.com/golib/aws/service/request"
+	"github.com/golib/aws/service/signer/v4"
+)
+
+func newClient(rec *recorder.Recorder, endpoint string) *client.Client {
+	svc := awstesting.NewClient(service.NewConfig().
+		WithRegion("us-west-2").
+		WithCredentials(credentials.NewStaticCredentials("AKID", "SECRET", "SESSION")).
+		WithMaxRetries(0).
+		WithHTTPClient(rec.HTTPClient()))
+	svc.ClientInfo.Endpoint = endpoint
+	svc.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+
+	return svc
+}
+
+func send(svc *client.Client, path, body string) (*request.Request, string, error) {
+	op := &request.Operation{Name: "PutItem", HTTPMethod: "POST", HTTPPath: path}
+	req := svc.NewRequest(op, nil, nil)
+	req.SetStringBody(body)
+
+	if err := req.Send(); err != nil {
+		return req, "", err
+	}
+	defer req.HTTPResponse.Body.Close()
+
+	b, err := ioutil.ReadAll(req.HTTPResponse.Body)
+	return req, string(b), err
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cassette := filepath.Join(dir, "testdata", "cassette.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Aws-Request-Id", "abc123")
+		w.Write([]byte(r.URL.Path + ":" + string(b)))
+	}))
+
+	rec, err := recorder.New(cassette, recorder.ModeAuto)
+	assert.NoError(t, err)
+	assert.Equal(t, recorder.ModeRecord, rec.Mode())
+
+	svc := newClient(rec, server.URL)
+	_, body, err := send(svc, "/items?limit=1", "first")
+	assert.NoError(t, err)
+	assert.Equal(t, "/items:first", body)
+
+	_, body, err = send(svc, "/items?limit=1", "second")
+	assert.NoError(t, err)
+	assert.Equal(t, "/items:second", body)
+
+	assert.NoError(t, rec.Stop())
+	server.Close()
+
+	b, err := ioutil.ReadFile(cassette)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "SECRET")
+	assert.NotContains(t, string(b), "AKID")
+	assert.NotContains(t, string(b), "SESSION")
+	assert.Contains(t, string(b), "REDACTED")
+
+	// Replay, in a different order, without the server.
+	rec, err = recorder.New(cassette, recorder.ModeAuto)
+	assert.NoError(t, err)
+	assert.Equal(t, recorder.ModeReplay, rec.Mode())
+
+	svc = newClient(rec, server.URL)
+	req, body, err := send(svc, "/items?limit=1", "second")
+	assert.NoError(t, err)
+	assert.Equal(t, "/items:second", body)
+	assert.Equal(t, "abc123", req.HTTPResponse.Header.Get("X-Aws-Request-Id"))
+
+	_, body, err = send(svc, "/items?limit=1", "first")
+	assert.NoError(t, err)
+	assert.Equal(t, "/items:first", body)
+
+	// Each interaction is only replayed once.
+	_, _, err = send(svc, "/items?limit=1", "first")
+	assert.Error(t, err)
+}
+
+func TestReplayNoMatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cassette := filepath.Join(dir, "cassette.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	rec, err := recorder.New(cassette, recorder.ModeRecord)
+	assert.NoError(t, err)
+	_, _, err = send(newClient(rec, server.URL), "/items?limit=1", "body")
+	assert.NoError(t, err)
+	assert.NoError(t, rec.Stop())
+
+	rec, err = recorder.New(cassette, recorder.ModeReplay)
+	assert.NoError(t, err)
+
+	cases := []struct{ path, body string }{
+		{"/other?limit=1", "body"},
+		{"/items?limit=2", "body"},
+		{"/items?limit=1", "other body"},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest("POST", server.URL+c.path, nil)
+		assert.NoError(t, err)
+		req.Body = ioutil.NopCloser(strings.NewReader(c.body))
+
+		_, err = rec.RoundTrip(req)
+		assert.Error(t, err, c.path)
+		assert.Equal(t, recorder.InteractionNotFoundErrCode, err.(awserr.Error).Code(), c.path)
+	}
+}
+
+func TestReplayMissingCassette(t *testing.T) {
+	_, err := recorder.New(filepath.Join(os.TempDir(), "recorder-missing", "cassette.json"), recorder.ModeReplay)
+	assert.Error(t, err)
+}
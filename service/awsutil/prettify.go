@@ -8,7 +8,12 @@ import (
 	"strings"
 )
 
-// Prettify returns the string representation of a value.
+// sensitiveValue is written in place of the values of struct fields tagged
+// `sensitive:"true"`, such as passwords and secret keys.
+const sensitiveValue = "<sensitive>"
+
+// Prettify returns the string representation of a value. The values of struct
+// fields tagged `sensitive:"true"` are masked.
 func Prettify(i interface{}) string {
 	var buf bytes.Buffer
 	prettify(reflect.ValueOf(i), 0, &buf)
@@ -53,7 +58,11 @@ func prettify(v reflect.Value, indent int, buf *bytes.Buffer) {
 			val := v.FieldByName(n)
 			buf.WriteString(strings.Repeat(" ", indent+2))
 			buf.WriteString(n + ": ")
-			prettify(val, indent+2, buf)
+			if isSensitive(v.Type(), n) {
+				buf.WriteString(sensitiveValue)
+			} else {
+				prettify(val, indent+2, buf)
+			}
 
 			if i < len(names)-1 {
 				buf.WriteString(",\n")
@@ -106,3 +115,10 @@ func prettify(v reflect.Value, indent int, buf *bytes.Buffer) {
 		fmt.Fprintf(buf, format, v.Interface())
 	}
 }
+
+// isSensitive returns whether the struct field with the name is tagged
+// `sensitive:"true"`.
+func isSensitive(t reflect.Type, name string) bool {
+	f, ok := t.FieldByName(name)
+	return ok && f.Tag.Get("sensitive") == "true"
+}
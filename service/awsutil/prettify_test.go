@@ -0,0 +1,43 @@
+package awsutil
+
+import (
+	"testing"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+)
+
+type sensitiveInput struct {
+	UserName *string
+	Password *string `sensitive:"true"`
+	Token    *string `sensitive:"true"`
+	Nested   *sensitiveNested
+}
+
+type sensitiveNested struct {
+	SecretKey []byte `sensitive:"true"`
+	KeyID     *string
+}
+
+func TestPrettifySensitive(t *testing.T) {
+	in := sensitiveInput{
+		UserName: service.String("user"),
+		Password: service.String("hunter2"),
+		Nested: &sensitiveNested{
+			SecretKey: []byte("secret"),
+			KeyID:     service.String("key"),
+		},
+	}
+
+	for _, s := range []string{Prettify(in), StringValue(in)} {
+		assert.Contains(t, s, `UserName: "user"`)
+		assert.Contains(t, s, `Password: <sensitive>`)
+		assert.Contains(t, s, `SecretKey: <sensitive>`)
+		assert.Contains(t, s, `KeyID: "key"`)
+		assert.NotContains(t, s, "hunter2")
+		assert.NotContains(t, s, "secret")
+
+		// Unset sensitive fields are omitted like other fields.
+		assert.NotContains(t, s, "Token")
+	}
+}
@@ -7,7 +7,8 @@ import (
 	"strings"
 )
 
-// StringValue returns the string representation of a value.
+// StringValue returns the string representation of a value. The values of
+// struct fields tagged `sensitive:"true"` are masked.
 func StringValue(i interface{}) string {
 	var buf bytes.Buffer
 	stringValue(reflect.ValueOf(i), 0, &buf)
@@ -41,7 +42,11 @@ func stringValue(v reflect.Value, indent int, buf *bytes.Buffer) {
 			val := v.FieldByName(n)
 			buf.WriteString(strings.Repeat(" ", indent+2))
 			buf.WriteString(n + ": ")
-			stringValue(val, indent+2, buf)
+			if isSensitive(v.Type(), n) {
+				buf.WriteString(sensitiveValue)
+			} else {
+				stringValue(val, indent+2, buf)
+			}
 
 			if i < len(names)-1 {
 				buf.WriteString(",\n")
@@ -0,0 +1,330 @@
+// Package circuitbreaker provides an opt-in circuit breaker for service
+// requests. A circuit is kept per service endpoint and API operation, and
+// opens after consecutive retryable failures, failing requests fast with a
+// CircuitOpenErrCode error instead of sending them.
+//
+//	breaker := circuitbreaker.New()
+//	breaker.AddHandlers(&svc.Handlers)
+//
+// After the open timeout has elapsed the circuit is half-open, and a limited
+// number of probe requests are sent. A successful probe closes the circuit,
+// a failed one opens it again.
+package circuitbreaker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/request"
+)
+
+const (
+	// CircuitOpenErrCode is the error code returned for requests which were
+	// not sent because the circuit of their endpoint and operation is open.
+	CircuitOpenErrCode = "CircuitOpen"
+
+	// DefaultFailureThreshold is the default number of consecutive retryable
+	// failures which open a circuit.
+	DefaultFailureThreshold = 5
+
+	// DefaultOpenTimeout is the default duration a circuit stays open before
+	// probe requests are allowed.
+	DefaultOpenTimeout = 30 * time.Second
+
+	// DefaultHalfOpenRequests is the default number of concurrent probe
+	// requests allowed while a circuit is half-open.
+	DefaultHalfOpenRequests = 1
+)
+
+// A State is the state of a circuit.
+type State int
+
+// States of a circuit.
+const (
+	// StateClosed allows all requests to be sent.
+	StateClosed State = iota
+
+	// StateOpen fails all requests without sending them.
+	StateOpen
+
+	// StateHalfOpen allows a limited number of probe requests to be sent.
+	StateHalfOpen
+)
+
+// String returns the string representation of the circuit state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown circuit state"
+	}
+}
+
+// A Key identifies the circuit of a service endpoint and API operation.
+type Key struct {
+	Endpoint  string
+	Operation string
+}
+
+// circuit is the state of a single circuit.
+type circuit struct {
+	state    State
+	failures int
+	openedAt time.Time
+	probes   int
+
+	// generation is incremented whenever the probes are reset, so probes
+	// of a previous half-open state are not released twice.
+	generation int
+}
+
+// resetProbes resets the number of probe requests sent.
+func (c *circuit) resetProbes() {
+	c.probes = 0
+	c.generation++
+}
+
+// A probe is a request sent while its circuit is half-open.
+type probe struct {
+	circuit    *circuit
+	generation int
+}
+
+// A Breaker is a set of circuits keyed by the service endpoint and API
+// operation of requests. A Breaker is safe to use concurrently, and may be
+// shared by multiple service clients.
+type Breaker struct {
+	// FailureThreshold is the number of consecutive retryable failures
+	// which open a circuit.
+	FailureThreshold int
+
+	// OpenTimeout is the duration a circuit stays open before it becomes
+	// half-open.
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is the number of concurrent probe requests allowed
+	// while a circuit is half-open.
+	HalfOpenRequests int
+
+	mu       sync.Mutex
+	circuits map[Key]*circuit
+	probes   map[*request.Request]probe
+	now      func() time.Time
+}
+
+// New returns a new Breaker with the default thresholds. The options are
+// applied to the Breaker in the order they are provided.
+func New(options ...func(*Breaker)) *Breaker {
+	b := &Breaker{
+		FailureThreshold: DefaultFailureThreshold,
+		OpenTimeout:      DefaultOpenTimeout,
+		HalfOpenRequests: DefaultHalfOpenRequests,
+		circuits:         map[Key]*circuit{},
+		probes:           map[*request.Request]probe{},
+		now:              time.Now,
+	}
+
+	for _, option := range options {
+		option(b)
+	}
+
+	return b
+}
+
+// AddHandlers injects the circuit breaker's handlers into the handlers.
+// Requests are checked against their circuit after being signed, and the
+// outcome of each attempt is recorded once the attempt completes.
+func (b *Breaker) AddHandlers(handlers *request.Handlers) {
+	handlers.Sign.PushBackNamed(request.NamedHandler{
+		Name: "circuitbreaker.AllowHandler", Fn: b.allowHandler,
+	})
+	handlers.CompleteAttempt.PushBackNamed(request.NamedHandler{
+		Name: "circuitbreaker.AttemptHandler", Fn: b.attemptHandler,
+	})
+	handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "circuitbreaker.ReleaseHandler", Fn: b.release,
+	})
+}
+
+// State returns the current state of the circuit for the service endpoint
+// and API operation.
+func (b *Breaker) State(endpoint, operation string) State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[Key{Endpoint: endpoint, Operation: operation}]
+	if !ok {
+		return StateClosed
+	}
+
+	return b.currentState(c)
+}
+
+// States returns the current state of every circuit the breaker has
+// recorded requests for. Useful for reporting health checks.
+func (b *Breaker) States() map[Key]State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := make(map[Key]State, len(b.circuits))
+	for k, c := range b.circuits {
+		states[k] = b.currentState(c)
+	}
+
+	return states
+}
+
+// Reset closes all circuits of the breaker.
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.circuits = map[Key]*circuit{}
+	b.probes = map[*request.Request]probe{}
+}
+
+// currentState returns the state of the circuit, moving an open circuit to
+// half-open once its open timeout has elapsed. Must be called with the lock
+// held.
+func (b *Breaker) currentState(c *circuit) State {
+	if c.state == StateOpen && b.now().Sub(c.openedAt) >= b.OpenTimeout {
+		c.state = StateHalfOpen
+		c.resetProbes()
+	}
+
+	return c.state
+}
+
+// allow returns true if a request for the circuit of the key may be sent.
+// The probe is returned if the request is sent while the circuit is
+// half-open, and must be released once the request completes.
+func (b *Breaker) allow(k Key) (bool, *probe) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[k]
+	if !ok {
+		return true, nil
+	}
+
+	switch b.currentState(c) {
+	case StateOpen:
+		return false, nil
+	case StateHalfOpen:
+		if c.probes >= b.HalfOpenRequests {
+			return false, nil
+		}
+		c.probes++
+		return true, &probe{circuit: c, generation: c.generation}
+	}
+
+	return true, nil
+}
+
+// record updates the circuit of the key with the outcome of a request.
+func (b *Breaker) record(k Key, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[k]
+	if !ok {
+		if !failed {
+			return
+		}
+		c = &circuit{}
+		b.circuits[k] = c
+	}
+
+	state := b.currentState(c)
+	if !failed {
+		c.state = StateClosed
+		c.failures = 0
+		c.resetProbes()
+		return
+	}
+
+	c.failures++
+	if state == StateHalfOpen || c.failures >= b.FailureThreshold {
+		c.state = StateOpen
+		c.openedAt = b.now()
+		c.resetProbes()
+	}
+}
+
+// release frees the probe slot held by the request, if any. The slot is
+// not freed if the circuit's probes have been reset since the request was
+// allowed.
+func (b *Breaker) release(r *request.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p, ok := b.probes[r]
+	if !ok {
+		return
+	}
+	delete(b.probes, r)
+
+	if c := p.circuit; c.generation == p.generation && c.probes > 0 {
+		c.probes--
+	}
+}
+
+func requestKey(r *request.Request) Key {
+	return Key{Endpoint: r.ClientInfo.Endpoint, Operation: r.Operation.Name}
+}
+
+// allowHandler is a Sign handler failing the request if its circuit is open.
+// Presigned requests are not sent by the SDK, and are never failed.
+func (b *Breaker) allowHandler(r *request.Request) {
+	if r.Error != nil || r.ExpireTime != 0 {
+		return
+	}
+
+	k := requestKey(r)
+	allowed, p := b.allow(k)
+	if !allowed {
+		r.Error = awserr.New(CircuitOpenErrCode,
+			fmt.Sprintf("circuit open for %s %s", k.Endpoint, k.Operation), nil)
+		r.Retryable = service.Bool(false)
+		return
+	}
+
+	if p != nil {
+		b.mu.Lock()
+		b.probes[r] = *p
+		b.mu.Unlock()
+	}
+}
+
+// attemptHandler is a CompleteAttempt handler recording the outcome of the
+// request's attempt, and releasing its probe. Only retryable failures, such
+// as throttling or server errors, count towards opening the circuit. Other
+// failures show the service is responding, and close the circuit. Canceled
+// attempts are not recorded.
+func (b *Breaker) attemptHandler(r *request.Request) {
+	defer b.release(r)
+
+	if r.Error == nil {
+		b.record(requestKey(r), false)
+		return
+	}
+
+	if err, ok := r.Error.(awserr.Error); ok && err.Code() == request.CanceledErrCode {
+		return
+	}
+
+	failed := service.BoolValue(r.Retryable)
+	if r.Retryable == nil && r.HTTPResponse != nil {
+		failed = r.ShouldRetry(r)
+	}
+
+	b.record(requestKey(r), failed)
+}
@@ -0,0 +1,192 @@
+package circuitbreaker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/client"
+	"github.com/golib/aws/service/request"
+)
+
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) now() time.Time { return c.t }
+
+func newBreakerClient(b *Breaker, status *int) (*client.Client, *int) {
+	reqNum := 0
+	s := awstesting.NewClient(service.NewConfig().
+		WithMaxRetries(2).
+		WithSleepDelay(func(time.Duration) {}))
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		reqNum++
+		r.HTTPResponse = &http.Response{StatusCode: *status, Header: http.Header{}}
+	})
+	s.Handlers.ValidateResponse.PushBack(func(r *request.Request) {
+		if r.HTTPResponse.StatusCode >= 400 && r.Error == nil {
+			r.Error = awserr.New("ClientError", "client error", nil)
+		}
+	})
+	b.AddHandlers(&s.Handlers)
+
+	return s, &reqNum
+}
+
+func send(s *client.Client, name string) error {
+	return s.NewRequest(&request.Operation{Name: name}, nil, nil).Send()
+}
+
+func TestBreakerOpensOnRetryableFailures(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	b := New(func(b *Breaker) {
+		b.FailureThreshold = 3
+		b.now = clock.now
+	})
+
+	status := 503
+	s, reqNum := newBreakerClient(b, &status)
+
+	// The 3rd attempt of the first request opens the circuit.
+	err := send(s, "GetItem")
+	assert.Error(t, err)
+	assert.Equal(t, 3, *reqNum)
+	assert.Equal(t, StateOpen, b.State("http://endpoint", "GetItem"))
+	assert.Equal(t, StateClosed, b.State("http://endpoint", "PutItem"))
+
+	*reqNum = 0
+	err = send(s, "GetItem")
+	assert.Error(t, err)
+	assert.Equal(t, CircuitOpenErrCode, err.(awserr.Error).Code())
+	assert.Equal(t, 0, *reqNum)
+
+	assert.Equal(t, map[Key]State{
+		{Endpoint: "http://endpoint", Operation: "GetItem"}: StateOpen,
+	}, b.States())
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	b := New(func(b *Breaker) {
+		b.FailureThreshold = 1
+		b.OpenTimeout = time.Minute
+		b.now = clock.now
+	})
+
+	status := 503
+	s, reqNum := newBreakerClient(b, &status)
+
+	send(s, "GetItem")
+	assert.Equal(t, StateOpen, b.State("http://endpoint", "GetItem"))
+
+	// A failed probe opens the circuit again.
+	clock.t = clock.t.Add(time.Minute)
+	assert.Equal(t, StateHalfOpen, b.State("http://endpoint", "GetItem"))
+
+	*reqNum = 0
+	send(s, "GetItem")
+	assert.Equal(t, 1, *reqNum)
+	assert.Equal(t, StateOpen, b.State("http://endpoint", "GetItem"))
+
+	// A successful probe closes the circuit.
+	clock.t = clock.t.Add(time.Minute)
+	status = 200
+	assert.NoError(t, send(s, "GetItem"))
+	assert.Equal(t, StateClosed, b.State("http://endpoint", "GetItem"))
+}
+
+func TestBreakerHalfOpenLimitsProbes(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	b := New(func(b *Breaker) {
+		b.FailureThreshold = 1
+		b.now = clock.now
+	})
+
+	k := Key{Endpoint: "http://endpoint", Operation: "GetItem"}
+	b.record(k, true)
+	clock.t = clock.t.Add(DefaultOpenTimeout)
+
+	allowed, p := b.allow(k)
+	assert.True(t, allowed)
+	assert.NotNil(t, p)
+
+	allowed, _ = b.allow(k)
+	assert.False(t, allowed, "expect only one probe while half-open")
+}
+
+func TestBreakerCanceledProbeReleased(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	b := New(func(b *Breaker) {
+		b.FailureThreshold = 1
+		b.now = clock.now
+	})
+
+	k := Key{Endpoint: "http://endpoint", Operation: "GetItem"}
+	b.record(k, true)
+	clock.t = clock.t.Add(DefaultOpenTimeout)
+
+	status := 200
+	s, reqNum := newBreakerClient(b, &status)
+
+	// The probe is canceled while being sent.
+	ctx, cancel := context.WithCancel(context.Background())
+	r := s.NewRequest(&request.Operation{Name: "GetItem"}, nil, nil)
+	r.SetContext(ctx)
+	r.Handlers.Send.PushFront(func(r *request.Request) {
+		cancel()
+		r.Error = awserr.New("RequestError", "send request failed", ctx.Err())
+	})
+
+	err := r.Send()
+	assert.Error(t, err)
+	assert.Equal(t, request.CanceledErrCode, err.(awserr.Error).Code())
+	assert.Equal(t, StateHalfOpen, b.State(k.Endpoint, k.Operation))
+
+	// The next request is allowed as a probe, and closes the circuit.
+	*reqNum = 0
+	assert.NoError(t, send(s, "GetItem"))
+	assert.Equal(t, 1, *reqNum)
+	assert.Equal(t, StateClosed, b.State(k.Endpoint, k.Operation))
+}
+
+func TestBreakerIgnoresNonRetryableFailures(t *testing.T) {
+	b := New(func(b *Breaker) {
+		b.FailureThreshold = 1
+	})
+
+	status := 400
+	s, reqNum := newBreakerClient(b, &status)
+
+	for i := 0; i < 3; i++ {
+		err := send(s, "GetItem")
+		assert.Equal(t, "ClientError", err.(awserr.Error).Code())
+	}
+	assert.Equal(t, 3, *reqNum)
+	assert.Equal(t, StateClosed, b.State("http://endpoint", "GetItem"))
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b := New(func(b *Breaker) {
+		b.FailureThreshold = 2
+	})
+
+	k := Key{Endpoint: "http://endpoint", Operation: "GetItem"}
+	b.record(k, true)
+	b.record(k, false)
+	b.record(k, true)
+	assert.Equal(t, StateClosed, b.State(k.Endpoint, k.Operation))
+
+	b.record(k, true)
+	assert.Equal(t, StateOpen, b.State(k.Endpoint, k.Operation))
+
+	b.Reset()
+	assert.Equal(t, StateClosed, b.State(k.Endpoint, k.Operation))
+}
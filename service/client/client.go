@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/golib/aws/service"
@@ -31,8 +32,18 @@ type Client struct {
 	Handlers request.Handlers
 }
 
-// New will return a pointer to a new initialized service client.
+// New will return a pointer to a new initialized service client. If the
+// ClientInfo does not set any SignerOptions the service's default signer
+// options are used.
 func New(cfg service.Config, info metadata.ClientInfo, handlers request.Handlers, options ...func(*Client)) *Client {
+	if info.SignerOptions.IsZero() {
+		signingName := info.SigningName
+		if signingName == "" {
+			signingName = info.ServiceName
+		}
+		info.SignerOptions = metadata.DefaultSignerOptions(signingName)
+	}
+
 	svc := &Client{
 		Config:     cfg,
 		ClientInfo: info,
@@ -52,6 +63,10 @@ func New(cfg service.Config, info metadata.ClientInfo, handlers request.Handlers
 			maxRetries = 3
 		}
 		svc.Retryer = DefaultRetryer{NumMaxRetries: maxRetries}
+
+		if cfg.RetryMode != nil {
+			svc.addRetryModeHandlers(*cfg.RetryMode)
+		}
 	}
 
 	svc.AddDebugHandlers()
@@ -64,9 +79,68 @@ func New(cfg service.Config, info metadata.ClientInfo, handlers request.Handlers
 }
 
 // NewRequest returns a new Request pointer for the service API
-// operation and parameters.
-func (c *Client) NewRequest(operation *request.Operation, params interface{}, data interface{}) *request.Request {
-	return request.New(c.Config, c.ClientInfo, c.Handlers, c.Retryer, operation, params, data)
+// operation and parameters. The options are applied to the request
+// in the order they are provided.
+func (c *Client) NewRequest(operation *request.Operation, params interface{}, data interface{}, opts ...request.Option) *request.Request {
+	r := request.New(c.Config, c.ClientInfo, c.Handlers, c.Retryer, operation, params, data)
+	c.applyOptions(r, opts...)
+
+	return r
+}
+
+// NewRequestWithContext returns a new Request pointer for the service API
+// operation and parameters, which will be canceled when ctx is done. The
+// options are applied to the request in the order they are provided.
+func (c *Client) NewRequestWithContext(ctx context.Context, operation *request.Operation, params interface{}, data interface{}, opts ...request.Option) *request.Request {
+	r := request.NewWithContext(ctx, c.Config, c.ClientInfo, c.Handlers, c.Retryer, operation, params, data)
+	c.applyOptions(r, opts...)
+
+	return r
+}
+
+// applyOptions applies the options to the request. If an option enables
+// debug logging for a request of a client without it, the debug handlers
+// are added to the request.
+func (c *Client) applyOptions(r *request.Request, opts ...request.Option) {
+	r.ApplyOptions(opts...)
+
+	if !c.Config.LogLevel.AtLeast(service.LogDebug) && r.Config.LogLevel.AtLeast(service.LogDebug) {
+		r.Handlers.Send.PushFront(logRequest)
+		r.Handlers.Send.PushBack(logResponse)
+	}
+}
+
+// addRetryModeHandlers injects the handlers implementing the retry mode into
+// the service. The retry quota and send rate of the mode are shared by all
+// requests made by the service client. Successes are recorded by Complete
+// handlers, once all of the service's Unmarshal handlers have run.
+func (c *Client) addRetryModeHandlers(mode service.RetryMode) {
+	if mode != service.RetryModeStandard && mode != service.RetryModeAdaptive {
+		return
+	}
+
+	quota := newRetryQuota(retryQuotaCapacity)
+	c.Handlers.AfterRetry.PushFrontNamed(request.NamedHandler{
+		Name: "core.RetryQuotaHandler", Fn: quota.retryHandler,
+	})
+	c.Handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "core.RetryQuotaSuccessHandler", Fn: quota.successHandler,
+	})
+
+	if mode != service.RetryModeAdaptive {
+		return
+	}
+
+	limiter := newAdaptiveRateLimiter()
+	c.Handlers.Send.PushFrontNamed(request.NamedHandler{
+		Name: "core.ClientRateLimitHandler", Fn: limiter.sendHandler,
+	})
+	c.Handlers.AfterRetry.PushFrontNamed(request.NamedHandler{
+		Name: "core.ClientRateUpdateHandler", Fn: limiter.updateHandler,
+	})
+	c.Handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "core.ClientRateSuccessHandler", Fn: limiter.successHandler,
+	})
 }
 
 // AddDebugHandlers injects debug logging handlers into the service to log request
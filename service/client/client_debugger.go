@@ -1,31 +1,31 @@
 package client
 
 import (
-	"fmt"
-	"io/ioutil"
+	"bytes"
 	"net/http/httputil"
 
 	"github.com/golib/aws/service"
 	"github.com/golib/aws/service/request"
 )
 
-const (
-	logReqMsg = `DEBUG: Request %s/%s Details:
----[ REQUEST POST-SIGN ]-----------------------------
-%s
------------------------------------------------------`
+func logRequest(r *request.Request) {
+	if !r.Config.LogLevel.AtLeast(service.LogDebug) {
+		return
+	}
 
-	logReqErrMsg = `DEBUG ERROR: Request %s/%s:
----[ REQUEST DUMP ERROR ]-----------------------------
-%s
------------------------------------------------------`
-)
+	redactor := r.Config.LogRedactor()
 
-func logRequest(r *request.Request) {
-	logBody := r.Config.LogLevel.Matches(service.LogDebugWithHTTPBody)
-	dumpedBody, err := httputil.DumpRequestOut(r.HTTPRequest, logBody)
+	// Dump a copy of the request with its sensitive headers and query
+	// parameters masked.
+	out := *r.HTTPRequest
+	out.Header = redactor.Header(r.HTTPRequest.Header)
+	out.URL = redactor.URL(r.HTTPRequest.URL)
+
+	// A body which cannot be seeked would be consumed by logging it.
+	logBody := r.Config.LogLevel.Matches(service.LogDebugWithHTTPBody) && r.IsBodySeekable()
+	dumpedBody, err := httputil.DumpRequestOut(&out, logBody)
 	if err != nil {
-		r.Config.Logger.Log(fmt.Sprintf(logReqErrMsg, r.ClientInfo.ServiceName, r.Operation.Name, err))
+		r.Config.LogFields(service.SeverityError, "Failed to dump request", r.LogContext("error", err)...)
 		return
 	}
 
@@ -33,38 +33,54 @@ func logRequest(r *request.Request) {
 		// Reset the request body because dumpRequest will re-wrap the r.HTTPRequest's
 		// Body as a NoOpCloser and will not be reset after read by the HTTP
 		// client reader.
-		r.Body.Seek(r.BodyStart, 0)
-		r.HTTPRequest.Body = ioutil.NopCloser(r.Body)
+		r.ResetBody()
 	}
 
-	r.Config.Logger.Log(fmt.Sprintf(logReqMsg, r.ClientInfo.ServiceName, r.Operation.Name, string(dumpedBody)))
+	r.Config.LogFields(service.SeverityDebug, "Request",
+		r.LogContext("method", out.Method, "url", out.URL.String(),
+			"request", truncateDumpBody(redactor, dumpedBody))...)
 }
 
-const (
-	logRespMsg = `DEBUG: Response %s/%s Details:
----[ RESPONSE ]--------------------------------------
-%s
------------------------------------------------------`
-
-	logRespErrMsg = `DEBUG ERROR: Response %s/%s:
----[ RESPONSE DUMP ERROR ]-----------------------------
-%s
------------------------------------------------------`
-)
-
 func logResponse(r *request.Request) {
-	var msg = "no response data"
-	if r.HTTPResponse != nil {
-		logBody := r.Config.LogLevel.Matches(service.LogDebugWithHTTPBody)
-		dumpedBody, err := httputil.DumpResponse(r.HTTPResponse, logBody)
-		if err != nil {
-			r.Config.Logger.Log(fmt.Sprintf(logRespErrMsg, r.ClientInfo.ServiceName, r.Operation.Name, err))
-			return
+	if !r.Config.LogLevel.AtLeast(service.LogDebug) {
+		return
+	}
+
+	if r.HTTPResponse == nil {
+		fields := r.LogContext()
+		if r.Error != nil {
+			fields = append(fields, "error", r.Error)
 		}
+		r.Config.LogFields(service.SeverityDebug, "No response data", fields...)
+		return
+	}
+
+	redactor := r.Config.LogRedactor()
+
+	// Dump a copy of the response with its sensitive headers masked. The
+	// dumped body is replaced with a copy, which is set back on the response.
+	out := *r.HTTPResponse
+	out.Header = redactor.Header(r.HTTPResponse.Header)
+
+	logBody := r.Config.LogLevel.Matches(service.LogDebugWithHTTPBody)
+	dumpedBody, err := httputil.DumpResponse(&out, logBody)
+	r.HTTPResponse.Body = out.Body
+	if err != nil {
+		r.Config.LogFields(service.SeverityError, "Failed to dump response", r.LogContext("error", err)...)
+		return
+	}
 
-		msg = string(dumpedBody)
-	} else if r.Error != nil {
-		msg = r.Error.Error()
+	r.Config.LogFields(service.SeverityDebug, "Response",
+		r.LogContext("response", truncateDumpBody(redactor, dumpedBody))...)
+}
+
+// truncateDumpBody returns the dumped request or response, with its body
+// truncated by the redactor.
+func truncateDumpBody(redactor *service.LogRedactor, dump []byte) string {
+	i := bytes.Index(dump, []byte("\r\n\r\n"))
+	if i < 0 {
+		return string(dump)
 	}
-	r.Config.Logger.Log(fmt.Sprintf(logRespMsg, r.ClientInfo.ServiceName, r.Operation.Name, msg))
+
+	return string(dump[:i+4]) + redactor.Body(string(dump[i+4:]))
 }
@@ -0,0 +1,145 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awstesting/mock"
+	"github.com/golib/aws/service/request"
+)
+
+type logEntry struct {
+	severity service.LogSeverity
+	msg      string
+	fields   map[string]interface{}
+}
+
+type recordingLogger struct {
+	entries []logEntry
+}
+
+func (l *recordingLogger) LogFields(severity service.LogSeverity, msg string, keyvals ...interface{}) {
+	fields := map[string]interface{}{}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fields[keyvals[i].(string)] = keyvals[i+1]
+	}
+
+	l.entries = append(l.entries, logEntry{severity, msg, fields})
+}
+
+func (l *recordingLogger) messages() []string {
+	var msgs []string
+	for _, e := range l.entries {
+		msgs = append(msgs, e.msg)
+	}
+
+	return msgs
+}
+
+func TestDebugHandlersStructuredLogging(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(
+		mock.ServerErrorResponse(),
+		mock.JSONResponse(200, `{}`),
+	)
+
+	logger := &recordingLogger{}
+	svc := server.NewClient(service.NewConfig().
+		WithMaxRetries(1).
+		WithLogLevel(service.LogDebugWithRequestErrors | service.LogDebugWithRequestRetries).
+		WithStructuredLogger(logger))
+
+	req := svc.NewRequest(&request.Operation{Name: "ListTables", HTTPMethod: "POST", HTTPPath: "/"}, nil, nil)
+	req.HTTPRequest.Header.Set("X-Aws-Target", "Mock.ListTables")
+	req.Handlers.Retry.PushBack(func(r *request.Request) {
+		r.RetryDelay = 0
+	})
+
+	err := req.Send()
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{
+		"Request", "Response", "Request failed",
+		"Retrying request", "Request", "Response",
+	}, logger.messages())
+
+	for _, e := range logger.entries {
+		assert.Equal(t, service.SeverityDebug, e.severity)
+		assert.Equal(t, "Mock", e.fields["service"])
+		assert.Equal(t, "ListTables", e.fields["operation"])
+	}
+
+	request1, response1, failed := logger.entries[0], logger.entries[1], logger.entries[2]
+	assert.Equal(t, 1, request1.fields["attempt"])
+	assert.Equal(t, "POST", request1.fields["method"])
+	assert.Contains(t, request1.fields["request"], "X-Aws-Target: Mock.ListTables")
+
+	assert.Equal(t, 500, response1.fields["status"])
+	assert.Contains(t, response1.fields["response"], "500 Internal Server Error")
+
+	assert.Equal(t, "Validate Response", failed.fields["stage"])
+	assert.Equal(t, true, failed.fields["retrying"])
+	assert.NotNil(t, failed.fields["error"])
+
+	retrying, response2 := logger.entries[3], logger.entries[5]
+	assert.Equal(t, 2, retrying.fields["attempt"])
+	assert.Equal(t, 2, response2.fields["attempt"])
+	assert.Equal(t, 200, response2.fields["status"])
+}
+
+func TestDebugHandlersRedaction(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(
+		mock.JSONResponse(200, `{"TableNames":["a","b"]}`).WithHeader("Set-Cookie", "session=secret"),
+	)
+
+	logger := &recordingLogger{}
+	svc := server.NewClient(service.NewConfig().
+		WithLogLevel(service.LogDebugWithHTTPBody).
+		WithLogMaxBodyBytes(8).
+		WithLogRedactedHeaders("X-Api-Key").
+		WithStructuredLogger(logger))
+
+	req := svc.NewRequest(&request.Operation{Name: "ListTables", HTTPMethod: "POST", HTTPPath: "/"}, nil, nil)
+	req.HTTPRequest.Header.Set("X-Aws-Target", "Mock.ListTables")
+	req.HTTPRequest.Header.Set("X-Api-Key", "api-key")
+	req.SetStringBody(`{"Limit":10,"ExclusiveStartTableName":"t"}`)
+
+	var body []byte
+	req.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+		body, _ = ioutil.ReadAll(r.HTTPResponse.Body)
+	})
+
+	err := req.Send()
+	assert.Nil(t, err)
+
+	if !assert.Equal(t, []string{"Request", "Response"}, logger.messages()) {
+		return
+	}
+
+	dump := logger.entries[0].fields["request"].(string)
+	assert.Contains(t, dump, "Authorization: REDACTED")
+	assert.Contains(t, dump, "X-Aws-Security-Token: REDACTED")
+	assert.Contains(t, dump, "X-Api-Key: REDACTED")
+	assert.NotContains(t, dump, "SESSION")
+	assert.NotContains(t, dump, "api-key")
+	assert.Contains(t, dump, `{"Limit"...(34 bytes truncated)`)
+
+	dump = logger.entries[1].fields["response"].(string)
+	assert.Contains(t, dump, "Set-Cookie: REDACTED")
+	assert.Contains(t, dump, `{"TableN...(16 bytes truncated)`)
+
+	// The request was sent, and the response read, in full.
+	reqs := server.Requests()
+	if assert.Len(t, reqs, 1) {
+		assert.Equal(t, `{"Limit":10,"ExclusiveStartTableName":"t"}`, string(reqs[0].Body))
+		assert.Equal(t, "api-key", reqs[0].Header.Get("X-Api-Key"))
+	}
+	assert.Equal(t, `{"TableNames":["a","b"]}`, string(body))
+}
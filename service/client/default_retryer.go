@@ -2,6 +2,8 @@ package client
 
 import (
 	"math/rand"
+	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -20,8 +22,18 @@ import (
 //
 type DefaultRetryer struct {
 	NumMaxRetries int
+
+	// MaxRetryAfterDelay caps the retry delay requested by the service with
+	// a Retry-After or X-Aws-Retry-After response header. Defaults to
+	// DefaultMaxRetryAfterDelay if zero.
+	MaxRetryAfterDelay time.Duration
 }
 
+// DefaultMaxRetryAfterDelay is the default maximum delay the DefaultRetryer
+// will wait before retrying when the service requests a delay with a
+// response header.
+const DefaultMaxRetryAfterDelay = 5 * time.Minute
+
 // MaxRetries returns the number of maximum returns the service will use to make
 // an individual API request.
 func (d DefaultRetryer) MaxRetries() int {
@@ -30,8 +42,18 @@ func (d DefaultRetryer) MaxRetries() int {
 
 var securityRandomSeed = rand.New(&lockedSource{src: rand.NewSource(time.Now().UnixNano())})
 
-// RetryRules returns the delay duration before retrying this request again
+// RetryRules returns the delay duration before retrying this request again.
+// The delay requested by the service's response headers is used if present,
+// otherwise the delay is computed with exponential backoff and jitter.
 func (d DefaultRetryer) RetryRules(r *request.Request) time.Duration {
+	maxDelay := d.MaxRetryAfterDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxRetryAfterDelay
+	}
+	if delay, ok := retryAfterDelay(r, time.Now(), maxDelay); ok {
+		return delay
+	}
+
 	// Set the upper limit of delay in retrying at ~five minutes
 	minTime := 30
 	throttle := d.shouldThrottle(r)
@@ -68,6 +90,59 @@ func (d DefaultRetryer) shouldThrottle(r *request.Request) bool {
 	return r.IsErrorThrottle()
 }
 
+// retryAfterDelay returns the delay requested by the service's response
+// with the X-Aws-Retry-After header, in milliseconds, or the Retry-After
+// header, in seconds or as an HTTP-date, capped to the max delay. False is
+// returned if neither header is set to a valid value.
+func retryAfterDelay(r *request.Request, now time.Time, maxDelay time.Duration) (time.Duration, bool) {
+	if r.HTTPResponse == nil || r.HTTPResponse.Header == nil {
+		return 0, false
+	}
+
+	if v := r.HTTPResponse.Header.Get("X-Aws-Retry-After"); v != "" {
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil && ms >= 0 {
+			// Capped before converting, large values overflow the duration.
+			if ms > int64(maxDelay/time.Millisecond) {
+				return maxDelay, true
+			}
+			if delay := time.Duration(ms) * time.Millisecond; delay < maxDelay {
+				return delay, true
+			}
+			return maxDelay, true
+		}
+	}
+
+	v := r.HTTPResponse.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		if secs > int64(maxDelay/time.Second) {
+			return maxDelay, true
+		}
+		if delay := time.Duration(secs) * time.Second; delay < maxDelay {
+			return delay, true
+		}
+		return maxDelay, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		delay := t.Sub(now)
+		if delay < 0 {
+			delay = 0
+		} else if delay > maxDelay {
+			delay = maxDelay
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
 // lockedSource is a thread-safe implementation of rand.Source
 type lockedSource struct {
 	lk  sync.Mutex
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service/request"
+)
+
+func newRetryAfterRequest(headers map[string]string) *request.Request {
+	resp := &http.Response{StatusCode: 503, Header: http.Header{}}
+	for k, v := range headers {
+		resp.Header.Set(k, v)
+	}
+
+	return &request.Request{HTTPResponse: resp}
+}
+
+func TestRetryRulesRetryAfterSeconds(t *testing.T) {
+	d := DefaultRetryer{NumMaxRetries: 3}
+
+	r := newRetryAfterRequest(map[string]string{"Retry-After": "7"})
+	assert.Equal(t, 7*time.Second, d.RetryRules(r))
+}
+
+func TestRetryRulesRetryAfterHTTPDate(t *testing.T) {
+	now := time.Date(2016, 5, 24, 0, 0, 0, 0, time.UTC)
+
+	r := newRetryAfterRequest(map[string]string{
+		"Retry-After": now.Add(30 * time.Second).Format(http.TimeFormat),
+	})
+	delay, ok := retryAfterDelay(r, now, time.Hour)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	r = newRetryAfterRequest(map[string]string{
+		"Retry-After": now.Add(-30 * time.Second).Format(http.TimeFormat),
+	})
+	delay, ok = retryAfterDelay(r, now, time.Hour)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), delay)
+}
+
+func TestRetryRulesRetryAfterMilliseconds(t *testing.T) {
+	d := DefaultRetryer{NumMaxRetries: 3}
+
+	r := newRetryAfterRequest(map[string]string{
+		"X-Aws-Retry-After": "1500",
+		"Retry-After":       "7",
+	})
+	assert.Equal(t, 1500*time.Millisecond, d.RetryRules(r))
+}
+
+func TestRetryRulesRetryAfterCapped(t *testing.T) {
+	r := newRetryAfterRequest(map[string]string{"Retry-After": "3600"})
+
+	d := DefaultRetryer{NumMaxRetries: 3}
+	assert.Equal(t, DefaultMaxRetryAfterDelay, d.RetryRules(r))
+
+	d.MaxRetryAfterDelay = 10 * time.Second
+	assert.Equal(t, 10*time.Second, d.RetryRules(r))
+
+	r = newRetryAfterRequest(map[string]string{
+		"Retry-After": time.Now().Add(24 * time.Hour).Format(http.TimeFormat),
+	})
+	assert.Equal(t, 10*time.Second, d.RetryRules(r))
+}
+
+func TestRetryRulesRetryAfterSubSecondCap(t *testing.T) {
+	d := DefaultRetryer{NumMaxRetries: 3, MaxRetryAfterDelay: 500 * time.Millisecond}
+
+	r := newRetryAfterRequest(map[string]string{"Retry-After": "0"})
+	assert.Equal(t, time.Duration(0), d.RetryRules(r))
+
+	r = newRetryAfterRequest(map[string]string{"Retry-After": "1"})
+	assert.Equal(t, 500*time.Millisecond, d.RetryRules(r))
+
+	r = newRetryAfterRequest(map[string]string{"X-Aws-Retry-After": "200"})
+	assert.Equal(t, 200*time.Millisecond, d.RetryRules(r))
+
+	r = newRetryAfterRequest(map[string]string{"X-Aws-Retry-After": "800"})
+	assert.Equal(t, 500*time.Millisecond, d.RetryRules(r))
+}
+
+func TestRetryRulesRetryAfterOverflowCapped(t *testing.T) {
+	d := DefaultRetryer{NumMaxRetries: 3}
+
+	r := newRetryAfterRequest(map[string]string{"Retry-After": "99999999999"})
+	assert.Equal(t, DefaultMaxRetryAfterDelay, d.RetryRules(r))
+
+	r = newRetryAfterRequest(map[string]string{"X-Aws-Retry-After": "99999999999999999"})
+	assert.Equal(t, DefaultMaxRetryAfterDelay, d.RetryRules(r))
+}
+
+func TestRetryRulesRetryAfterInvalid(t *testing.T) {
+	d := DefaultRetryer{NumMaxRetries: 3}
+
+	for _, v := range []string{"", "soon", "-5"} {
+		r := newRetryAfterRequest(map[string]string{"Retry-After": v})
+		delay := d.RetryRules(r)
+
+		// Falls back to the throttled jitter backoff for the first retry.
+		assert.True(t, delay >= 500*time.Millisecond && delay < time.Second,
+			"%q: expect jitter backoff delay, got %v", v, delay)
+	}
+}
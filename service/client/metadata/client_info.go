@@ -9,4 +9,62 @@ type ClientInfo struct {
 	SigningRegion string
 	JSONVersion   string
 	TargetPrefix  string
+	SignerOptions SignerOptions
+}
+
+// SignerOptions wraps the service specific options the request signer
+// should use when signing the client's requests.
+type SignerOptions struct {
+	// Disables the escaping of the request's URI path when building the
+	// canonical string of the signature. S3 is an example of a service
+	// which does not need the path escaped.
+	DisableURIPathEscaping bool
+
+	// Disables moving HTTP header key/value pairs from the request header
+	// to the request's query string when presigning requests.
+	DisableHeaderHoisting bool
+
+	// Always adds the request body's digest to the request as the
+	// X-Aws-Content-Sha256 header. Required by services such as S3.
+	IncludeContentSHA256Header bool
+
+	// Signs the request with the UNSIGNED-PAYLOAD body digest instead of
+	// computing the SHA256 of the request body.
+	UnsignedPayload bool
+
+	// Additional headers which must be signed as HTTP headers, and will
+	// never be hoisted into the query string of presigned requests.
+	SignedHeaders []string
+
+	// Additional headers which will not be included in the signature.
+	IgnoredHeaders []string
+}
+
+// IsZero returns true if none of the signer options are set.
+func (o SignerOptions) IsZero() bool {
+	return !o.DisableURIPathEscaping && !o.DisableHeaderHoisting &&
+		!o.IncludeContentSHA256Header && !o.UnsignedPayload &&
+		len(o.SignedHeaders) == 0 && len(o.IgnoredHeaders) == 0
+}
+
+// defaultSignerOptions are the signer options of services which require
+// them, keyed by the service's signing name.
+var defaultSignerOptions = map[string]SignerOptions{
+	"s3": {
+		DisableURIPathEscaping:     true,
+		IncludeContentSHA256Header: true,
+	},
+	"glacier": {
+		IncludeContentSHA256Header: true,
+	},
+	"mock": {
+		IncludeContentSHA256Header: true,
+	},
+}
+
+// DefaultSignerOptions returns the signer options the requests of the
+// service are signed with if its client does not set any. The zero value
+// is returned for services without default options.
+func DefaultSignerOptions(signingName string) SignerOptions {
+	return defaultSignerOptions[signingName]
 }
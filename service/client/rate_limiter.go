@@ -0,0 +1,209 @@
+package client
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golib/aws/service/request"
+)
+
+const (
+	// rateSmoothing is the weight of the latest measurement when smoothing
+	// the measured send rate.
+	rateSmoothing = 0.8
+
+	// rateBeta is the factor the send rate is reduced by when throttled.
+	rateBeta = 0.7
+
+	// rateScaleConstant scales how fast the send rate recovers after being
+	// throttled.
+	rateScaleConstant = 0.4
+
+	// rateMinFillRate is the minimum send rate, in requests per second.
+	rateMinFillRate = 0.5
+
+	// rateBucketDuration is the duration, in seconds, of the buckets the
+	// send rate is measured over.
+	rateBucketDuration = 0.5
+)
+
+// adaptiveRateLimiter is a client side send rate limiter following a CUBIC
+// congestion control strategy. The limiter is only enabled once a throttling
+// response has been received. The rate is then reduced multiplicatively on
+// each throttling response, and grows back along a cubic curve towards, and
+// beyond, the rate at which the last throttle occurred.
+type adaptiveRateLimiter struct {
+	mu sync.Mutex
+
+	enabled bool
+
+	// Token bucket of requests which may be sent.
+	fillRate        float64
+	maxCapacity     float64
+	currentCapacity float64
+	lastRefilled    time.Time
+
+	// Measurement of the rate requests are sent at.
+	measuredTxRate   float64
+	lastTxRateBucket float64
+	requestCount     int64
+
+	// State of the CUBIC rate curve.
+	lastMaxRate      float64
+	lastThrottleTime time.Time
+	timeWindow       float64
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+func newAdaptiveRateLimiter() *adaptiveRateLimiter {
+	now := time.Now()
+
+	return &adaptiveRateLimiter{
+		lastTxRateBucket: math.Floor(timeFloat64Seconds(now)),
+		lastThrottleTime: now,
+		now:              time.Now,
+		sleep:            request.SleepWithContext,
+	}
+}
+
+// acquire waits until a request may be sent. Requests are never delayed
+// until the limiter has been enabled by a throttling response. An error is
+// returned if the context is canceled while waiting.
+func (l *adaptiveRateLimiter) acquire(ctx context.Context) error {
+	for {
+		delay, ok := l.tryAcquire(1)
+		if ok {
+			return nil
+		}
+
+		if err := l.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// tryAcquire takes the amount of tokens from the bucket if available. If
+// not, the delay until enough tokens will be available is returned.
+func (l *adaptiveRateLimiter) tryAcquire(amount float64) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.enabled {
+		return 0, true
+	}
+
+	l.refill()
+	if amount <= l.currentCapacity {
+		l.currentCapacity -= amount
+		return 0, true
+	}
+
+	// Round up so the wait never truncates to zero, which would spin.
+	wait := (amount - l.currentCapacity) / l.fillRate
+	return time.Duration(math.Ceil(wait * float64(time.Second))), false
+}
+
+// update adjusts the send rate with the outcome of a request attempt.
+func (l *adaptiveRateLimiter) update(throttled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.updateMeasuredRate()
+
+	var calculatedRate float64
+	if throttled {
+		rateToUse := l.measuredTxRate
+		if l.enabled {
+			rateToUse = math.Min(l.measuredTxRate, l.fillRate)
+		}
+
+		l.lastMaxRate = rateToUse
+		l.calculateTimeWindow()
+		l.lastThrottleTime = l.now()
+		calculatedRate = l.cubicThrottle(rateToUse)
+		l.enabled = true
+	} else {
+		l.calculateTimeWindow()
+		calculatedRate = l.cubicSuccess(l.now())
+	}
+
+	l.updateRate(math.Min(calculatedRate, 2*l.measuredTxRate))
+}
+
+func (l *adaptiveRateLimiter) cubicThrottle(rateToUse float64) float64 {
+	return rateToUse * rateBeta
+}
+
+func (l *adaptiveRateLimiter) cubicSuccess(t time.Time) float64 {
+	dt := t.Sub(l.lastThrottleTime).Seconds()
+	return rateScaleConstant*math.Pow(dt-l.timeWindow, 3) + l.lastMaxRate
+}
+
+func (l *adaptiveRateLimiter) calculateTimeWindow() {
+	l.timeWindow = math.Cbrt(l.lastMaxRate * (1 - rateBeta) / rateScaleConstant)
+}
+
+func (l *adaptiveRateLimiter) updateMeasuredRate() {
+	t := timeFloat64Seconds(l.now())
+	timeBucket := math.Floor(t/rateBucketDuration) * rateBucketDuration
+
+	l.requestCount++
+	if timeBucket > l.lastTxRateBucket {
+		currentRate := float64(l.requestCount) / (timeBucket - l.lastTxRateBucket)
+		l.measuredTxRate = currentRate*rateSmoothing + l.measuredTxRate*(1-rateSmoothing)
+		l.requestCount = 0
+		l.lastTxRateBucket = timeBucket
+	}
+}
+
+func (l *adaptiveRateLimiter) updateRate(newRPS float64) {
+	l.refill()
+
+	l.fillRate = math.Max(newRPS, rateMinFillRate)
+	l.maxCapacity = math.Max(newRPS, 1)
+	l.currentCapacity = math.Min(l.currentCapacity, l.maxCapacity)
+}
+
+func (l *adaptiveRateLimiter) refill() {
+	t := l.now()
+	if l.lastRefilled.IsZero() {
+		l.lastRefilled = t
+		return
+	}
+
+	fill := t.Sub(l.lastRefilled).Seconds() * l.fillRate
+	l.currentCapacity = math.Min(l.maxCapacity, l.currentCapacity+fill)
+	l.lastRefilled = t
+}
+
+// sendHandler is a Send handler delaying the request until the send rate
+// allows it to be sent.
+func (l *adaptiveRateLimiter) sendHandler(r *request.Request) {
+	// A canceled context will fail the request when it is sent.
+	l.acquire(r.Context())
+}
+
+// updateHandler is an AfterRetry handler updating the send rate with the
+// outcome of the request's failed attempt.
+func (l *adaptiveRateLimiter) updateHandler(r *request.Request) {
+	throttled := r.Error != nil &&
+		(r.IsErrorThrottle() || (r.HTTPResponse != nil && r.HTTPResponse.StatusCode == 429))
+
+	l.update(throttled)
+}
+
+// successHandler is a Complete handler updating the send rate when a
+// request succeeds.
+func (l *adaptiveRateLimiter) successHandler(r *request.Request) {
+	if r.Error == nil {
+		l.update(false)
+	}
+}
+
+func timeFloat64Seconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
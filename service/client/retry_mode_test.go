@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/client/metadata"
+	"github.com/golib/aws/service/request"
+)
+
+func newRetryModeClient(mode service.RetryMode, maxRetries int, statusCodes func(n int) int) (*Client, *int) {
+	cfg := service.NewConfig().
+		WithMaxRetries(maxRetries).
+		WithRetryMode(mode).
+		WithSleepDelay(func(time.Duration) {})
+
+	var handlers request.Handlers
+	handlers.AfterRetry.PushBack(func(r *request.Request) {
+		r.ResolveRetryable()
+		if r.WillRetry() {
+			r.RetryCount++
+			r.Error = nil
+		}
+	})
+
+	c := New(*cfg, metadata.ClientInfo{Endpoint: "http://endpoint"}, handlers)
+
+	reqNum := 0
+	c.Handlers.Send.PushBack(func(r *request.Request) {
+		code := statusCodes(reqNum)
+		reqNum++
+		r.HTTPResponse = &http.Response{StatusCode: code, Header: http.Header{}}
+	})
+	c.Handlers.ValidateResponse.PushBack(func(r *request.Request) {
+		switch code := r.HTTPResponse.StatusCode; {
+		case code == 429:
+			r.Error = awserr.New("ThrottlingException", "rate exceeded", nil)
+		case code >= 400:
+			r.Error = awserr.New("ServiceUnavailable", "unavailable", nil)
+		}
+	})
+
+	return c, &reqNum
+}
+
+func TestRetryQuota(t *testing.T) {
+	q := newRetryQuota(10)
+
+	assert.True(t, q.acquire(5))
+	assert.True(t, q.acquire(5))
+	assert.False(t, q.acquire(5))
+	assert.Equal(t, 0, q.available())
+
+	q.release(5)
+	q.release(100)
+	assert.Equal(t, 10, q.available())
+}
+
+func TestStandardRetryModeDrainsQuota(t *testing.T) {
+	c, reqNum := newRetryModeClient(service.RetryModeStandard, 3, func(int) int { return 503 })
+
+	// Each failing request retries 3 times until the quota is exhausted.
+	for i := 0; i < retryQuotaCapacity/(3*retryQuotaCost); i++ {
+		err := c.NewRequest(&request.Operation{Name: "Operation"}, nil, nil).Send()
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 4*(retryQuotaCapacity/(3*retryQuotaCost)), *reqNum)
+
+	*reqNum = 0
+	for i := 0; i < 3; i++ {
+		c.NewRequest(&request.Operation{Name: "Operation"}, nil, nil).Send()
+	}
+	assert.True(t, *reqNum < 12, "expect retries to be limited by quota, got %d requests", *reqNum)
+}
+
+func TestStandardRetryModeRefillsQuota(t *testing.T) {
+	c, reqNum := newRetryModeClient(service.RetryModeStandard, 3, func(n int) int {
+		if n%2 == 0 {
+			return 503
+		}
+		return 200
+	})
+
+	for i := 0; i < 2*retryQuotaCapacity/retryQuotaCost; i++ {
+		err := c.NewRequest(&request.Operation{Name: "Operation"}, nil, nil).Send()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 4*retryQuotaCapacity/retryQuotaCost, *reqNum)
+}
+
+func TestStandardRetryModeUnmarshalErrorNotRefunded(t *testing.T) {
+	c, reqNum := newRetryModeClient(service.RetryModeStandard, 3, func(n int) int {
+		if n%2 == 0 {
+			return 503
+		}
+		return 200
+	})
+
+	// The service's unmarshaler runs after the client's handlers were added.
+	c.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+		r.Error = awserr.New("SerializationError", "failed to unmarshal", nil)
+		r.Retryable = service.Bool(false)
+	})
+
+	// Requests failing to unmarshal do not refund their retry, so the quota
+	// is exhausted.
+	for i := 0; i < retryQuotaCapacity/retryQuotaCost; i++ {
+		err := c.NewRequest(&request.Operation{Name: "Operation"}, nil, nil).Send()
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2*retryQuotaCapacity/retryQuotaCost, *reqNum)
+
+	*reqNum = 0
+	err := c.NewRequest(&request.Operation{Name: "Operation"}, nil, nil).Send()
+	assert.Error(t, err)
+	assert.Equal(t, 1, *reqNum, "expect no retry once the quota is exhausted")
+}
+
+func TestStandardRetryModeChargesOnlyRetries(t *testing.T) {
+	c, reqNum := newRetryModeClient(service.RetryModeStandard, 3, func(int) int { return 503 })
+	quota := newRetryQuota(retryQuotaCapacity)
+	c.Handlers.AfterRetry.SwapNamed(request.NamedHandler{Name: "core.RetryQuotaHandler", Fn: quota.retryHandler})
+	c.Handlers.Send.PushFront(func(r *request.Request) {
+		ioutil.ReadAll(r.HTTPRequest.Body)
+	})
+
+	// A consumed streaming body cannot be retried, so no tokens are spent.
+	r := c.NewRequest(&request.Operation{Name: "Operation", HTTPMethod: "PUT"}, nil, nil)
+	r.SetStreamingBody(ioutil.NopCloser(strings.NewReader("body")), 4)
+
+	assert.Error(t, r.Send())
+	assert.Equal(t, 1, *reqNum)
+	assert.Equal(t, retryQuotaCapacity, quota.available())
+}
+
+func TestLegacyRetryModeUnlimited(t *testing.T) {
+	c, reqNum := newRetryModeClient(service.RetryModeLegacy, 3, func(int) int { return 503 })
+
+	for i := 0; i < retryQuotaCapacity; i++ {
+		c.NewRequest(&request.Operation{Name: "Operation"}, nil, nil).Send()
+	}
+	assert.Equal(t, 4*retryQuotaCapacity, *reqNum)
+}
+
+func TestAdaptiveRetryModeRetriesThrottle(t *testing.T) {
+	c, reqNum := newRetryModeClient(service.RetryModeAdaptive, 3, func(n int) int {
+		if n == 0 {
+			return 429
+		}
+		return 200
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	r := c.NewRequestWithContext(ctx, &request.Operation{Name: "Operation"}, nil, nil)
+	assert.NoError(t, r.Send())
+	assert.Equal(t, 2, *reqNum)
+	assert.Equal(t, 1, r.RetryCount)
+}
+
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) now() time.Time { return c.t }
+
+func (c *testClock) sleep(ctx context.Context, d time.Duration) error {
+	c.t = c.t.Add(d)
+	return ctx.Err()
+}
+
+func newTestRateLimiter(clock *testClock) *adaptiveRateLimiter {
+	l := newAdaptiveRateLimiter()
+	l.now = clock.now
+	l.sleep = clock.sleep
+	l.lastTxRateBucket = timeFloat64Seconds(clock.t)
+	l.lastThrottleTime = clock.t
+
+	return l
+}
+
+func TestAdaptiveRateLimiterDisabledUntilThrottled(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	l := newTestRateLimiter(clock)
+
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, l.acquire(context.Background()))
+		l.update(false)
+	}
+	assert.Equal(t, time.Unix(1000, 0), clock.t, "expect no delay before throttled")
+	assert.False(t, l.enabled)
+}
+
+func TestAdaptiveRateLimiterThrottle(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	l := newTestRateLimiter(clock)
+
+	// Send 10 requests per second for a few seconds to measure the rate.
+	for i := 0; i < 30; i++ {
+		clock.t = clock.t.Add(100 * time.Millisecond)
+		l.update(false)
+	}
+	measured := l.measuredTxRate
+	assert.InDelta(t, 10, measured, 1)
+
+	clock.t = clock.t.Add(100 * time.Millisecond)
+	l.update(true)
+	assert.True(t, l.enabled)
+	assert.InDelta(t, measured*rateBeta, l.fillRate, 1)
+
+	// Sending faster than the fill rate must be delayed.
+	start := clock.t
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, l.acquire(context.Background()))
+	}
+	elapsed := clock.t.Sub(start).Seconds()
+	assert.True(t, elapsed >= float64(20-1)/l.fillRate-1,
+		"expect requests to be delayed by rate, elapsed %v, rate %v", elapsed, l.fillRate)
+
+	// The rate recovers after successful responses.
+	throttledRate := l.fillRate
+	for i := 0; i < 100; i++ {
+		clock.t = clock.t.Add(100 * time.Millisecond)
+		l.update(false)
+	}
+	assert.True(t, l.fillRate > throttledRate, "expect rate to recover, %v <= %v", l.fillRate, throttledRate)
+}
+
+func TestAdaptiveRateLimiterCanceled(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	l := newTestRateLimiter(clock)
+	l.update(true)
+	l.currentCapacity = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, context.Canceled, l.acquire(ctx))
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/request"
+)
+
+const (
+	// retryQuotaCapacity is the number of tokens a client's retry quota
+	// starts with, and can be refilled to.
+	retryQuotaCapacity = 500
+
+	// retryQuotaCost is the number of tokens a retry attempt costs, which
+	// is refunded once the retried request succeeds.
+	retryQuotaCost = 5
+
+	// retryQuotaNoRetryIncrement is the number of tokens added to the quota
+	// when a request succeeds without being retried.
+	retryQuotaNoRetryIncrement = 1
+)
+
+// retryQuota is a token bucket limiting the retries made by all requests of
+// a client. Retries are paid for with tokens, so when most requests fail the
+// quota drains and further failures are returned without retrying.
+type retryQuota struct {
+	mu       sync.Mutex
+	capacity int
+	tokens   int
+}
+
+func newRetryQuota(capacity int) *retryQuota {
+	return &retryQuota{
+		capacity: capacity,
+		tokens:   capacity,
+	}
+}
+
+// acquire removes the amount of tokens from the quota. False is returned,
+// and no tokens are removed, if the quota does not have enough tokens.
+func (q *retryQuota) acquire(amount int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if amount > q.tokens {
+		return false
+	}
+
+	q.tokens -= amount
+	return true
+}
+
+// release adds the amount of tokens back to the quota, up to its capacity.
+func (q *retryQuota) release(amount int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.tokens += amount
+	if q.tokens > q.capacity {
+		q.tokens = q.capacity
+	}
+}
+
+// available returns the number of tokens in the quota.
+func (q *retryQuota) available() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.tokens
+}
+
+// retryHandler is an AfterRetry handler which pays for the request's retry
+// from the quota. If the quota is exhausted the request is not retried. Only
+// requests which will be retried are charged, so the request's retry state
+// is resolved first.
+func (q *retryQuota) retryHandler(r *request.Request) {
+	r.ResolveRetryable()
+	if !r.WillRetry() {
+		return
+	}
+
+	if !q.acquire(retryQuotaCost) {
+		r.Retryable = service.Bool(false)
+	}
+}
+
+// successHandler is a Complete handler refilling the quota when a request
+// succeeds. The cost of a successful retry is refunded.
+func (q *retryQuota) successHandler(r *request.Request) {
+	if r.Error != nil {
+		return
+	}
+
+	if r.RetryCount > 0 {
+		q.release(retryQuotaCost)
+	} else {
+		q.release(retryQuotaNoRetryIncrement)
+	}
+}
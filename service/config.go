@@ -74,6 +74,38 @@ type Config struct {
 	// standard out.
 	Logger Logger
 
+	// The structured logger to write log entries to, with their severity
+	// and key/value fields such as the operation and request ID. If set it
+	// is used instead of Logger. Use NewSlogLogger to log to a log/slog
+	// Logger.
+	StructuredLogger StructuredLogger
+
+	// Additional headers whose values are masked in debug logs, in addition
+	// to DefaultLogRedactedHeaders.
+	LogRedactedHeaders []string
+
+	// Additional query parameters whose values are masked in debug logs, in
+	// addition to DefaultLogRedactedQuery.
+	LogRedactedQuery []string
+
+	// The maximum number of bytes of request and response bodies logged
+	// with LogDebugWithHTTPBody. Defaults to DefaultLogMaxBodyBytes. Set to
+	// a negative value to log bodies without truncating them.
+	LogMaxBodyBytes *int
+
+	// Set this to `true` to disable masking sensitive headers and query
+	// parameters, and truncating bodies, in debug logs. Defaults to `false`.
+	DisableLogRedaction *bool
+
+	// The response headers the request ID is read from, in order of
+	// preference. Defaults to corehandlers.DefaultRequestIDHeaders.
+	RequestIDHeaders []string
+
+	// The response headers the extended request ID, identifying the host
+	// which handled the request, is read from, in order of preference.
+	// Defaults to corehandlers.DefaultHostIDHeaders.
+	HostIDHeaders []string
+
 	// The maximum number of times that a request will be retried for failures.
 	// Defaults to -1, which defers the max retry setting to the service
 	// specific configuration.
@@ -95,18 +127,35 @@ type Config struct {
 	//
 	Retryer RequestRetryer
 
+	// RetryMode selects the retry behavior of the default retryer used when
+	// Retryer is not set. Defaults to RetryModeLegacy.
+	//
+	// Can also be set with the retry_mode key of the shared config file.
+	RetryMode *RetryMode
+
 	// Disables semantic parameter validation, which validates input for
 	// missing required fields and/or other semantic request input errors.
 	DisableParamValidation *bool
 
 	// Disables the computation of request and response checksums, e.g.,
-	// CRC32 checksums in Amazon DynamoDB.
+	// CRC32 checksums in Amazon DynamoDB. Disables the Content-MD5 and
+	// X-Aws-Checksum-* request headers, and the validation of the response
+	// body against the X-Aws-Checksum-* response headers.
 	DisableComputeChecksums *bool
 
 	// Disables the computation of request body, e.g.,
-	// sha256 checksums of request body.
+	// sha256 checksums of request body. The request is signed with an
+	// UNSIGNED-PAYLOAD body digest instead.
 	DisableBodyDigest *bool
 
+	// Set this to `true` to disable gzip compression of request bodies for
+	// API operations which accept compressed requests.
+	DisableRequestCompression *bool
+
+	// RequestMinCompressSizeBytes is the minimum size of a request body, in
+	// bytes, which is compressed. Defaults to 10240 bytes if not set.
+	RequestMinCompressSizeBytes *int64
+
 	// Set this to `true` to force the request to use path-style addressing,
 	// i.e., `http://s3.amazonservice.com/BUCKET/KEY`. By default, the S3 client
 	// will use virtual hosted bucket addressing when possible
@@ -118,7 +167,8 @@ type Config struct {
 	ForcePathStyle *bool
 
 	// Set this to `true` to disable the SDK adding the `Expect: 100-Continue`
-	// header to PUT requests over 2MB of content. 100-Continue instructs the
+	// header to requests over 2MB of content, or the
+	// Expect100ContinueThreshold, and requests of unknown length. 100-Continue instructs the
 	// HTTP client not to send the body until the service responds with a
 	// `continue` status. This is useful to prevent sending the request body
 	// until after the request is authenticated, and validated.
@@ -133,6 +183,11 @@ type Config struct {
 	// with proxies or third party S3 compatible services.
 	Disable100Continue *bool
 
+	// Expect100ContinueThreshold is the size of the request body, in bytes,
+	// above which the `Expect: 100-Continue` header is added to requests.
+	// Defaults to 2MB if not set.
+	Expect100ContinueThreshold *int64
+
 	// Set this to `true` to enable S3 Accelerate feature. For all operations
 	// compatible with S3 Accelerate will use the accelerate endpoint for
 	// requests. Requests not compatible will fall back to normal S3 requests.
@@ -167,11 +222,25 @@ type Config struct {
 	//     })
 	UseDualStack *bool
 
+	// Set this to `true` to recover panics of request handlers. A recovered
+	// panic fails the request with a request.HandlerPanicErrCode error,
+	// including the name of the handler and the stack of the panic, instead
+	// of crashing the process.
+	RecoverHandlerPanics *bool
+
+	// Set this to `true` to record how long each request handler took to
+	// run in the request's HandlerTimings.
+	RecordHandlerTimings *bool
+
 	// SleepDelay is an override for the func the SDK will call when sleeping
 	// during the lifecycle of a request. Specifically this will be used for
 	// request delays. This value should only be used for testing. To adjust
 	// the delay of a request see the aws/client.DefaultRetryer and
 	// aws/request.Retryer.
+	//
+	// If not set the retry delay will be waited out with
+	// request.SleepWithContext, which returns early when the request's
+	// Context is canceled.
 	SleepDelay func(time.Duration)
 }
 
@@ -241,6 +310,13 @@ func (c *Config) WithMaxRetries(max int) *Config {
 	return c
 }
 
+// WithRetryMode sets a config RetryMode value returning a Config pointer
+// for chaining.
+func (c *Config) WithRetryMode(mode RetryMode) *Config {
+	c.RetryMode = &mode
+	return c
+}
+
 // WithDisableParamValidation sets a config DisableParamValidation value
 // returning a Config pointer for chaining.
 func (c *Config) WithDisableParamValidation(disable bool) *Config {
@@ -262,6 +338,20 @@ func (c *Config) WithDisableBodyDigest(diable bool) *Config {
 	return c
 }
 
+// WithDisableRequestCompression sets a config DisableRequestCompression
+// value returning a Config pointer for chaining.
+func (c *Config) WithDisableRequestCompression(disable bool) *Config {
+	c.DisableRequestCompression = &disable
+	return c
+}
+
+// WithRequestMinCompressSizeBytes sets a config RequestMinCompressSizeBytes
+// value returning a Config pointer for chaining.
+func (c *Config) WithRequestMinCompressSizeBytes(size int64) *Config {
+	c.RequestMinCompressSizeBytes = &size
+	return c
+}
+
 // WithLogLevel sets a config LogLevel value returning a Config pointer for
 // chaining.
 func (c *Config) WithLogLevel(level LogLevelType) *Config {
@@ -276,6 +366,67 @@ func (c *Config) WithLogger(logger Logger) *Config {
 	return c
 }
 
+// WithStructuredLogger sets a config StructuredLogger value returning a
+// Config pointer for chaining.
+func (c *Config) WithStructuredLogger(logger StructuredLogger) *Config {
+	c.StructuredLogger = logger
+	return c
+}
+
+// WithLogRedactedHeaders sets a config LogRedactedHeaders value returning a
+// Config pointer for chaining.
+func (c *Config) WithLogRedactedHeaders(headers ...string) *Config {
+	c.LogRedactedHeaders = headers
+	return c
+}
+
+// WithLogRedactedQuery sets a config LogRedactedQuery value returning a
+// Config pointer for chaining.
+func (c *Config) WithLogRedactedQuery(params ...string) *Config {
+	c.LogRedactedQuery = params
+	return c
+}
+
+// WithLogMaxBodyBytes sets a config LogMaxBodyBytes value returning a Config
+// pointer for chaining.
+func (c *Config) WithLogMaxBodyBytes(n int) *Config {
+	c.LogMaxBodyBytes = &n
+	return c
+}
+
+// WithDisableLogRedaction sets a config DisableLogRedaction value returning
+// a Config pointer for chaining.
+func (c *Config) WithDisableLogRedaction(disable bool) *Config {
+	c.DisableLogRedaction = &disable
+	return c
+}
+
+// WithRequestIDHeaders sets a config RequestIDHeaders value returning a
+// Config pointer for chaining.
+func (c *Config) WithRequestIDHeaders(headers ...string) *Config {
+	c.RequestIDHeaders = headers
+	return c
+}
+
+// WithHostIDHeaders sets a config HostIDHeaders value returning a Config
+// pointer for chaining.
+func (c *Config) WithHostIDHeaders(headers ...string) *Config {
+	c.HostIDHeaders = headers
+	return c
+}
+
+// LogFields logs the entry to the config's StructuredLogger, or to its
+// Logger adapted with NewStructuredLogger if no StructuredLogger is set.
+// Nothing is logged if neither is set.
+func (c *Config) LogFields(severity LogSeverity, msg string, keyvals ...interface{}) {
+	switch {
+	case c.StructuredLogger != nil:
+		c.StructuredLogger.LogFields(severity, msg, keyvals...)
+	case c.Logger != nil:
+		NewStructuredLogger(c.Logger).LogFields(severity, msg, keyvals...)
+	}
+}
+
 // WithForcePathStyle sets a config ForcePathStyle value returning a Config
 // pointer for chaining.
 func (c *Config) WithForcePathStyle(force bool) *Config {
@@ -290,6 +441,13 @@ func (c *Config) WithDisable100Continue(disable bool) *Config {
 	return c
 }
 
+// WithExpect100ContinueThreshold sets a config Expect100ContinueThreshold
+// value returning a Config pointer for chaining.
+func (c *Config) WithExpect100ContinueThreshold(size int64) *Config {
+	c.Expect100ContinueThreshold = &size
+	return c
+}
+
 // WithUseAccelerate sets a config UseAccelerate value returning a Config
 // pointer for chaining.
 func (c *Config) WithUseAccelerate(enable bool) *Config {
@@ -304,6 +462,20 @@ func (c *Config) WithUseDualStack(enable bool) *Config {
 	return c
 }
 
+// WithRecoverHandlerPanics sets a config RecoverHandlerPanics value
+// returning a Config pointer for chaining.
+func (c *Config) WithRecoverHandlerPanics(enable bool) *Config {
+	c.RecoverHandlerPanics = &enable
+	return c
+}
+
+// WithRecordHandlerTimings sets a config RecordHandlerTimings value
+// returning a Config pointer for chaining.
+func (c *Config) WithRecordHandlerTimings(enable bool) *Config {
+	c.RecordHandlerTimings = &enable
+	return c
+}
+
 // WithSleepDelay overrides the function used to sleep while waiting for the
 // next retry. Defaults to time.Sleep.
 func (c *Config) WithSleepDelay(fn func(time.Duration)) *Config {
@@ -368,6 +540,34 @@ func mergeInConfig(dst *Config, other *Config) {
 		dst.Logger = other.Logger
 	}
 
+	if other.StructuredLogger != nil {
+		dst.StructuredLogger = other.StructuredLogger
+	}
+
+	if other.LogRedactedHeaders != nil {
+		dst.LogRedactedHeaders = other.LogRedactedHeaders
+	}
+
+	if other.LogRedactedQuery != nil {
+		dst.LogRedactedQuery = other.LogRedactedQuery
+	}
+
+	if other.LogMaxBodyBytes != nil {
+		dst.LogMaxBodyBytes = other.LogMaxBodyBytes
+	}
+
+	if other.DisableLogRedaction != nil {
+		dst.DisableLogRedaction = other.DisableLogRedaction
+	}
+
+	if other.RequestIDHeaders != nil {
+		dst.RequestIDHeaders = other.RequestIDHeaders
+	}
+
+	if other.HostIDHeaders != nil {
+		dst.HostIDHeaders = other.HostIDHeaders
+	}
+
 	if other.MaxRetries != nil {
 		dst.MaxRetries = other.MaxRetries
 	}
@@ -376,6 +576,10 @@ func mergeInConfig(dst *Config, other *Config) {
 		dst.Retryer = other.Retryer
 	}
 
+	if other.RetryMode != nil {
+		dst.RetryMode = other.RetryMode
+	}
+
 	if other.DisableParamValidation != nil {
 		dst.DisableParamValidation = other.DisableParamValidation
 	}
@@ -388,6 +592,14 @@ func mergeInConfig(dst *Config, other *Config) {
 		dst.DisableBodyDigest = other.DisableBodyDigest
 	}
 
+	if other.DisableRequestCompression != nil {
+		dst.DisableRequestCompression = other.DisableRequestCompression
+	}
+
+	if other.RequestMinCompressSizeBytes != nil {
+		dst.RequestMinCompressSizeBytes = other.RequestMinCompressSizeBytes
+	}
+
 	if other.ForcePathStyle != nil {
 		dst.ForcePathStyle = other.ForcePathStyle
 	}
@@ -396,6 +608,10 @@ func mergeInConfig(dst *Config, other *Config) {
 		dst.Disable100Continue = other.Disable100Continue
 	}
 
+	if other.Expect100ContinueThreshold != nil {
+		dst.Expect100ContinueThreshold = other.Expect100ContinueThreshold
+	}
+
 	if other.UseAccelerate != nil {
 		dst.UseAccelerate = other.UseAccelerate
 	}
@@ -404,6 +620,14 @@ func mergeInConfig(dst *Config, other *Config) {
 		dst.UseDualStack = other.UseDualStack
 	}
 
+	if other.RecoverHandlerPanics != nil {
+		dst.RecoverHandlerPanics = other.RecoverHandlerPanics
+	}
+
+	if other.RecordHandlerTimings != nil {
+		dst.RecordHandlerTimings = other.RecordHandlerTimings
+	}
+
 	if other.SleepDelay != nil {
 		dst.SleepDelay = other.SleepDelay
 	}
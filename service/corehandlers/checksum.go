@@ -0,0 +1,232 @@
+package corehandlers
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/request"
+)
+
+// Checksum algorithms supported for the RequestChecksumAlgorithm of an API
+// operation, and validated for responses.
+const (
+	ChecksumAlgorithmCRC32  = "CRC32"
+	ChecksumAlgorithmCRC32C = "CRC32C"
+	ChecksumAlgorithmSHA1   = "SHA1"
+	ChecksumAlgorithmSHA256 = "SHA256"
+)
+
+const (
+	// ChecksumMismatchErrCode is the error code for a response body which
+	// does not match the checksum returned by the service.
+	ChecksumMismatchErrCode = "ChecksumMismatch"
+
+	// ChecksumErrCode is the error code for a request body which a checksum
+	// could not be computed for.
+	ChecksumErrCode = "ChecksumError"
+
+	contentMD5Header     = "Content-Md5"
+	checksumHeaderPrefix = "X-Aws-Checksum-"
+)
+
+// responseChecksumAlgorithms are the checksum algorithms validated for
+// responses, in order of preference.
+var responseChecksumAlgorithms = []string{
+	ChecksumAlgorithmCRC32C,
+	ChecksumAlgorithmCRC32,
+	ChecksumAlgorithmSHA1,
+	ChecksumAlgorithmSHA256,
+}
+
+// newChecksumHash returns a new hash for the checksum algorithm, or nil if
+// the algorithm is not supported.
+func newChecksumHash(algorithm string) hash.Hash {
+	switch strings.ToUpper(algorithm) {
+	case ChecksumAlgorithmCRC32:
+		return crc32.NewIEEE()
+	case ChecksumAlgorithmCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case ChecksumAlgorithmSHA1:
+		return sha1.New()
+	case ChecksumAlgorithmSHA256:
+		return sha256.New()
+	default:
+		return nil
+	}
+}
+
+// checksumHeader returns the header the checksum of the algorithm is sent
+// and returned in, e.g. X-Aws-Checksum-Crc32.
+func checksumHeader(algorithm string) string {
+	return checksumHeaderPrefix + strings.ToLower(algorithm)
+}
+
+// ContentMD5Handler is a request handler computing the Content-MD5 header of
+// the request body, for API operations with HTTPChecksumRequired set. The
+// header is not computed if already set, or if the Config's
+// DisableComputeChecksums is enabled.
+var ContentMD5Handler = request.NamedHandler{
+	Name: "core.ContentMD5Handler",
+	Fn: func(r *request.Request) {
+		if service.BoolValue(r.Config.DisableComputeChecksums) || !r.Operation.HTTPChecksumRequired {
+			return
+		}
+		if r.HTTPRequest.Header.Get(contentMD5Header) != "" {
+			return
+		}
+
+		if !r.IsBodySeekable() {
+			r.Error = awserr.New(ChecksumErrCode,
+				"unable to compute Content-MD5 of request body which cannot be seeked", nil)
+			return
+		}
+
+		sum, err := bodyChecksum(r, md5.New())
+		if err != nil {
+			r.Error = awserr.New(ChecksumErrCode, "unable to compute Content-MD5 of request body", err)
+			return
+		}
+
+		r.HTTPRequest.Header.Set(contentMD5Header, sum)
+	},
+}
+
+// RequestChecksumHandler is a request handler computing the checksum of the
+// request body with the API operation's RequestChecksumAlgorithm, and sending
+// it in the X-Aws-Checksum-<algorithm> header. The checksum is not computed
+// if already set, if the body cannot be seeked, or if the Config's
+// DisableComputeChecksums is enabled.
+var RequestChecksumHandler = request.NamedHandler{
+	Name: "core.RequestChecksumHandler",
+	Fn: func(r *request.Request) {
+		algorithm := r.Operation.RequestChecksumAlgorithm
+		if service.BoolValue(r.Config.DisableComputeChecksums) || algorithm == "" {
+			return
+		}
+
+		header := checksumHeader(algorithm)
+		if r.HTTPRequest.Header.Get(header) != "" || !r.IsBodySeekable() {
+			return
+		}
+
+		h := newChecksumHash(algorithm)
+		if h == nil {
+			r.Error = awserr.New(ChecksumErrCode,
+				fmt.Sprintf("unsupported checksum algorithm %s", algorithm), nil)
+			return
+		}
+
+		sum, err := bodyChecksum(r, h)
+		if err != nil {
+			r.Error = awserr.New(ChecksumErrCode,
+				fmt.Sprintf("unable to compute %s checksum of request body", algorithm), err)
+			return
+		}
+
+		r.HTTPRequest.Header.Set(header, sum)
+	},
+}
+
+// ValidateResponseChecksumHandler is a response handler validating the
+// response body against the checksum returned by the service in a
+// X-Aws-Checksum-<algorithm> header, for API operations with
+// ResponseChecksumValidation set. The body is validated as it is read, and
+// reading the end of a body which does not match fails with a
+// ChecksumMismatchErrCode error. Not validated if the Config's
+// DisableComputeChecksums is enabled.
+var ValidateResponseChecksumHandler = request.NamedHandler{
+	Name: "core.ValidateResponseChecksumHandler",
+	Fn: func(r *request.Request) {
+		if service.BoolValue(r.Config.DisableComputeChecksums) || !r.Operation.ResponseChecksumValidation {
+			return
+		}
+		if r.Error != nil || r.HTTPResponse == nil || r.HTTPResponse.Body == nil {
+			return
+		}
+
+		for _, algorithm := range responseChecksumAlgorithms {
+			expect := r.HTTPResponse.Header.Get(checksumHeader(algorithm))
+			if expect == "" {
+				continue
+			}
+
+			// Checksums of multipart objects are not of the body, and
+			// cannot be validated.
+			if strings.Contains(expect, "-") {
+				return
+			}
+
+			r.HTTPResponse.Body = &checksumValidatingReader{
+				body:      r.HTTPResponse.Body,
+				algorithm: algorithm,
+				hash:      newChecksumHash(algorithm),
+				expect:    expect,
+			}
+			return
+		}
+	},
+}
+
+// bodyChecksum returns the base64 encoded sum of the request body, from the
+// body's current position, with the hash.
+func bodyChecksum(r *request.Request, h hash.Hash) (string, error) {
+	start, err := r.Body.Seek(0, 1)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(h, r.Body); err != nil {
+		return "", err
+	}
+
+	if _, err := r.Body.Seek(start, 0); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// checksumValidatingReader computes the checksum of a response body as it
+// is read, and returns an error once the end of the body is read if the
+// checksum does not match the expected one.
+type checksumValidatingReader struct {
+	body      io.ReadCloser
+	algorithm string
+	hash      hash.Hash
+	expect    string
+}
+
+// Read reads from the body, validating the checksum at the end of the body.
+func (c *checksumValidatingReader) Read(p []byte) (int, error) {
+	n, err := c.body.Read(p)
+	if n > 0 {
+		c.hash.Write(p[:n])
+	}
+
+	if err == io.EOF {
+		sum := c.hash.Sum(nil)
+		expect, decodeErr := base64.StdEncoding.DecodeString(c.expect)
+		if decodeErr != nil || !bytes.Equal(sum, expect) {
+			return n, awserr.New(ChecksumMismatchErrCode, fmt.Sprintf(
+				"response body %s checksum %s does not match expected %s",
+				c.algorithm, base64.StdEncoding.EncodeToString(sum), c.expect), decodeErr)
+		}
+	}
+
+	return n, err
+}
+
+// Close closes the body.
+func (c *checksumValidatingReader) Close() error {
+	return c.body.Close()
+}
@@ -0,0 +1,157 @@
+package corehandlers_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/corehandlers"
+	"github.com/golib/aws/service/request"
+)
+
+func newChecksumRequest(op *request.Operation, body string, cfgs ...*service.Config) *request.Request {
+	svc := awstesting.NewClient(cfgs...)
+	svc.Handlers.Clear()
+	svc.Handlers.Sign.PushBackNamed(corehandlers.ContentMD5Handler)
+	svc.Handlers.Sign.PushBackNamed(corehandlers.RequestChecksumHandler)
+
+	req := svc.NewRequest(op, nil, nil)
+	req.SetStringBody(body)
+	return req
+}
+
+func TestContentMD5Handler(t *testing.T) {
+	req := newChecksumRequest(&request.Operation{Name: "Operation", HTTPChecksumRequired: true}, "hello")
+	assert.NoError(t, req.Sign())
+	assert.Equal(t, "XUFAKrxLKna5cZ2REBfFkg==", req.HTTPRequest.Header.Get("Content-Md5"))
+
+	b, _ := ioutil.ReadAll(req.HTTPRequest.Body)
+	assert.Equal(t, "hello", string(b), "expect body to be rewound")
+}
+
+func TestContentMD5HandlerNotRequired(t *testing.T) {
+	req := newChecksumRequest(&request.Operation{Name: "Operation"}, "hello")
+	assert.NoError(t, req.Sign())
+	assert.Empty(t, req.HTTPRequest.Header.Get("Content-Md5"))
+}
+
+func TestContentMD5HandlerDisabled(t *testing.T) {
+	req := newChecksumRequest(&request.Operation{Name: "Operation", HTTPChecksumRequired: true}, "hello",
+		service.NewConfig().WithDisableComputeChecksums(true))
+	assert.NoError(t, req.Sign())
+	assert.Empty(t, req.HTTPRequest.Header.Get("Content-Md5"))
+}
+
+func TestContentMD5HandlerStreamingBody(t *testing.T) {
+	req := newChecksumRequest(&request.Operation{Name: "Operation", HTTPChecksumRequired: true}, "")
+	req.SetStreamingBody(ioutil.NopCloser(strings.NewReader("hello")), -1)
+
+	err := req.Sign()
+	assert.Error(t, err)
+	assert.Equal(t, corehandlers.ChecksumErrCode, err.(awserr.Error).Code())
+}
+
+func TestRequestChecksumHandler(t *testing.T) {
+	cases := map[string]string{
+		corehandlers.ChecksumAlgorithmCRC32:  "NhCmhg==",
+		corehandlers.ChecksumAlgorithmSHA1:   "qvTGHdzF6KLavt4PO0gs2a6pQ00=",
+		corehandlers.ChecksumAlgorithmSHA256: "LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=",
+	}
+
+	for algorithm, expect := range cases {
+		req := newChecksumRequest(&request.Operation{Name: "Operation", RequestChecksumAlgorithm: algorithm}, "hello")
+		assert.NoError(t, req.Sign())
+		assert.Equal(t, expect, req.HTTPRequest.Header.Get("X-Aws-Checksum-"+algorithm), algorithm)
+	}
+}
+
+func TestRequestChecksumHandlerUnsupported(t *testing.T) {
+	req := newChecksumRequest(&request.Operation{Name: "Operation", RequestChecksumAlgorithm: "MD4"}, "hello")
+
+	err := req.Sign()
+	assert.Error(t, err)
+	assert.Equal(t, corehandlers.ChecksumErrCode, err.(awserr.Error).Code())
+}
+
+func TestRequestChecksumHandlerDisabled(t *testing.T) {
+	req := newChecksumRequest(&request.Operation{Name: "Operation", RequestChecksumAlgorithm: "CRC32"}, "hello",
+		service.NewConfig().WithDisableComputeChecksums(true))
+	assert.NoError(t, req.Sign())
+	assert.Empty(t, req.HTTPRequest.Header.Get("X-Aws-Checksum-Crc32"))
+}
+
+func newResponseChecksumRequest(op *request.Operation, header, value, body string, cfgs ...*service.Config) *request.Request {
+	svc := awstesting.NewClient(cfgs...)
+	svc.Handlers.Clear()
+	svc.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+		}
+		r.HTTPResponse.Header.Set(header, value)
+	})
+	svc.Handlers.ValidateResponse.PushBackNamed(corehandlers.ValidateResponseChecksumHandler)
+	svc.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+		defer r.HTTPResponse.Body.Close()
+		if _, err := ioutil.ReadAll(r.HTTPResponse.Body); err != nil {
+			r.Error = err
+		}
+	})
+
+	return svc.NewRequest(op, nil, nil)
+}
+
+func TestValidateResponseChecksumHandler(t *testing.T) {
+	op := &request.Operation{Name: "Operation", ResponseChecksumValidation: true}
+
+	req := newResponseChecksumRequest(op, "X-Aws-Checksum-Crc32", "NhCmhg==", "hello")
+	assert.NoError(t, req.Send())
+
+	req = newResponseChecksumRequest(op, "X-Aws-Checksum-Sha256", "LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=", "hello")
+	assert.NoError(t, req.Send())
+}
+
+func TestValidateResponseChecksumHandlerMismatch(t *testing.T) {
+	op := &request.Operation{Name: "Operation", ResponseChecksumValidation: true}
+
+	req := newResponseChecksumRequest(op, "X-Aws-Checksum-Crc32", "NhCmhg==", "hellO")
+	err := req.Send()
+	assert.Error(t, err)
+	assert.Equal(t, corehandlers.ChecksumMismatchErrCode, err.(awserr.Error).Code())
+}
+
+func TestValidateResponseChecksumHandlerSkipped(t *testing.T) {
+	// Not enabled for the operation.
+	req := newResponseChecksumRequest(&request.Operation{Name: "Operation"},
+		"X-Aws-Checksum-Crc32", "NhCmhg==", "hellO")
+	assert.NoError(t, req.Send())
+
+	op := &request.Operation{Name: "Operation", ResponseChecksumValidation: true}
+
+	// Disabled by the config.
+	req = newResponseChecksumRequest(op, "X-Aws-Checksum-Crc32", "NhCmhg==", "hellO",
+		service.NewConfig().WithDisableComputeChecksums(true))
+	assert.NoError(t, req.Send())
+
+	// Checksum of a multipart object.
+	req = newResponseChecksumRequest(op, "X-Aws-Checksum-Crc32", "NhCmhg==-2", "hellO")
+	assert.NoError(t, req.Send())
+}
+
+func TestChecksumCRC32CRoundTrip(t *testing.T) {
+	req := newChecksumRequest(&request.Operation{Name: "Operation", RequestChecksumAlgorithm: "CRC32C"}, "hello")
+	assert.NoError(t, req.Sign())
+	sum := req.HTTPRequest.Header.Get("X-Aws-Checksum-Crc32c")
+	assert.NotEmpty(t, sum)
+
+	op := &request.Operation{Name: "Operation", ResponseChecksumValidation: true}
+	req = newResponseChecksumRequest(op, "X-Aws-Checksum-Crc32c", sum, "hello")
+	assert.NoError(t, req.Send())
+}
@@ -0,0 +1,93 @@
+package corehandlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/request"
+)
+
+// DefaultRequestMinCompressSizeBytes is the default minimum size of a
+// request body, in bytes, which is compressed.
+const DefaultRequestMinCompressSizeBytes int64 = 10240
+
+// CompressionErrCode is the error code for a request body which could not
+// be compressed.
+const CompressionErrCode = "CompressionError"
+
+// CompressRequestHandler compresses the request body with gzip for API
+// operations with RequestCompression set, if the body is at least the
+// Config's RequestMinCompressSizeBytes. The gzip encoding is added to the
+// request's Content-Encoding header. Not compressed if the Config's
+// DisableRequestCompression is set, the body cannot be seeked, or is already
+// gzip encoded.
+//
+// Must be run before BuildContentLengthHandler and the request is signed, so
+// the length and signature are of the compressed body. The compressed body
+// replaces the request's body, and is replayed when the request is retried.
+var CompressRequestHandler = request.NamedHandler{
+	Name: "core.CompressRequestHandler",
+	Fn: func(r *request.Request) {
+		if !r.Operation.RequestCompression || service.BoolValue(r.Config.DisableRequestCompression) {
+			return
+		}
+		if r.ExpireTime != 0 || r.Body == nil || !r.IsBodySeekable() {
+			return
+		}
+
+		encoding := r.HTTPRequest.Header.Get("Content-Encoding")
+		if strings.Contains(encoding, "gzip") {
+			// Already compressed, e.g. by a previous attempt.
+			return
+		}
+
+		minSize := DefaultRequestMinCompressSizeBytes
+		if r.Config.RequestMinCompressSizeBytes != nil {
+			minSize = *r.Config.RequestMinCompressSizeBytes
+		}
+
+		// The length is checked before reading, so bodies which are not
+		// compressed are not buffered.
+		length, err := seekerLen(r)
+		if err != nil {
+			r.Error = awserr.New(CompressionErrCode, "unable to compress request body", err)
+			return
+		}
+		if length < minSize {
+			return
+		}
+
+		var buf bytes.Buffer
+		err = gzipBody(&buf, r.Body)
+		if _, seekErr := r.Body.Seek(r.BodyStart, 0); err == nil {
+			err = seekErr
+		}
+		if err != nil {
+			r.Error = awserr.New(CompressionErrCode, "unable to compress request body", err)
+			return
+		}
+
+		r.SetBufferBody(buf.Bytes())
+		r.BodyStart = 0
+
+		if encoding != "" {
+			encoding += ", "
+		}
+		r.HTTPRequest.Header.Set("Content-Encoding", encoding+"gzip")
+
+		// The length of the body changed, and must be computed again.
+		r.HTTPRequest.Header.Del("Content-Length")
+	},
+}
+
+func gzipBody(w io.Writer, body io.Reader) error {
+	zw := gzip.NewWriter(w)
+	if _, err := io.Copy(zw, body); err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,161 @@
+package corehandlers_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/corehandlers"
+	"github.com/golib/aws/service/credentials"
+	"github.com/golib/aws/service/request"
+	"github.com/golib/aws/service/signer/v4"
+)
+
+func newCompressionRequest(op *request.Operation, body string, cfgs ...*service.Config) *request.Request {
+	svc := awstesting.NewClient(cfgs...)
+	svc.Handlers.Clear()
+	svc.Handlers.Sign.PushBackNamed(corehandlers.CompressRequestHandler)
+	svc.Handlers.Sign.PushBackNamed(corehandlers.BuildContentLengthHandler)
+
+	req := svc.NewRequest(op, nil, nil)
+	req.SetStringBody(body)
+	return req
+}
+
+func gunzip(t *testing.T, b []byte) string {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	assert.NoError(t, err)
+
+	out, err := ioutil.ReadAll(zr)
+	assert.NoError(t, err)
+	return string(out)
+}
+
+func TestCompressRequestHandler(t *testing.T) {
+	body := strings.Repeat(`{"key":"value"}`, 1000)
+
+	req := newCompressionRequest(&request.Operation{Name: "PutItems", RequestCompression: true}, body)
+	req.HTTPRequest.Header.Set("Content-Length", "15000")
+	assert.NoError(t, req.Sign())
+
+	assert.Equal(t, "gzip", req.HTTPRequest.Header.Get("Content-Encoding"))
+
+	b, err := ioutil.ReadAll(req.HTTPRequest.Body)
+	assert.NoError(t, err)
+	assert.True(t, len(b) < len(body))
+	assert.Equal(t, int64(len(b)), req.HTTPRequest.ContentLength)
+	assert.Equal(t, body, gunzip(t, b))
+
+	// Signing again, as when retried, does not compress the body again.
+	req.ResetBody()
+	assert.NoError(t, req.Sign())
+
+	b, err = ioutil.ReadAll(req.HTTPRequest.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, body, gunzip(t, b))
+}
+
+func TestCompressRequestHandlerAppendsEncoding(t *testing.T) {
+	req := newCompressionRequest(&request.Operation{Name: "PutItems", RequestCompression: true}, "hello",
+		service.NewConfig().WithRequestMinCompressSizeBytes(0))
+	req.HTTPRequest.Header.Set("Content-Encoding", "custom")
+	assert.NoError(t, req.Sign())
+
+	assert.Equal(t, "custom, gzip", req.HTTPRequest.Header.Get("Content-Encoding"))
+}
+
+func TestCompressRequestHandlerSkipped(t *testing.T) {
+	body := strings.Repeat("a", 20000)
+
+	cases := map[string]*request.Request{
+		"not supported": newCompressionRequest(&request.Operation{Name: "PutItems"}, body),
+		"disabled": newCompressionRequest(&request.Operation{Name: "PutItems", RequestCompression: true}, body,
+			service.NewConfig().WithDisableRequestCompression(true)),
+		"below minimum": newCompressionRequest(&request.Operation{Name: "PutItems", RequestCompression: true}, body,
+			service.NewConfig().WithRequestMinCompressSizeBytes(20001)),
+	}
+
+	for name, req := range cases {
+		assert.NoError(t, req.Sign(), name)
+		assert.Empty(t, req.HTTPRequest.Header.Get("Content-Encoding"), name)
+		assert.Equal(t, int64(len(body)), req.HTTPRequest.ContentLength, name)
+	}
+
+	req := newCompressionRequest(&request.Operation{Name: "PutItems", RequestCompression: true}, "")
+	req.SetStreamingBody(ioutil.NopCloser(strings.NewReader(body)), int64(len(body)))
+	assert.NoError(t, req.Sign())
+	assert.Empty(t, req.HTTPRequest.Header.Get("Content-Encoding"))
+}
+
+type readCounter struct {
+	*strings.Reader
+	reads int
+}
+
+func (r *readCounter) Read(p []byte) (int, error) {
+	r.reads++
+	return r.Reader.Read(p)
+}
+
+func TestCompressRequestHandlerBelowMinimumNotRead(t *testing.T) {
+	body := &readCounter{Reader: strings.NewReader(strings.Repeat("a", 100))}
+
+	req := newCompressionRequest(&request.Operation{Name: "PutItems", RequestCompression: true}, "")
+	req.SetReaderBody(body)
+	assert.NoError(t, req.Sign())
+
+	assert.Empty(t, req.HTTPRequest.Header.Get("Content-Encoding"))
+	assert.Equal(t, int64(100), req.HTTPRequest.ContentLength)
+	assert.Equal(t, 0, body.reads, "expect body below the minimum size not to be read")
+}
+
+func TestCompressRequestRetried(t *testing.T) {
+	body := strings.Repeat(`{"key":"value"}`, 1000)
+
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		b, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, body, gunzip(t, b))
+
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	svc := awstesting.NewClient(service.NewConfig().
+		WithRegion("us-west-2").
+		WithMaxRetries(1).
+		WithSleepDelay(func(time.Duration) {}))
+	svc.ClientInfo.Endpoint = server.URL
+
+	req := svc.NewRequest(&request.Operation{Name: "PutItems", HTTPMethod: "POST", HTTPPath: "/", RequestCompression: true}, nil, nil)
+	req.SetStringBody(body)
+
+	assert.NoError(t, req.Send())
+	assert.Equal(t, 2, attempts)
+}
+
+func TestCompressRequestSignsContentEncoding(t *testing.T) {
+	req := newCompressionRequest(&request.Operation{Name: "PutItems", RequestCompression: true}, "hello",
+		service.NewConfig().
+			WithRegion("us-west-2").
+			WithCredentials(credentials.NewStaticCredentials("AKID", "SECRET", "")).
+			WithRequestMinCompressSizeBytes(0))
+	req.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+
+	assert.NoError(t, req.Sign())
+	assert.Contains(t, req.HTTPRequest.Header.Get("Authorization"), "content-encoding")
+}
@@ -3,13 +3,13 @@ package corehandlers
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"regexp"
 	"runtime"
 	"strconv"
+	"time"
 
 	"github.com/golib/aws/service"
 	"github.com/golib/aws/service/awserr"
@@ -22,8 +22,10 @@ type lener interface {
 }
 
 // BuildContentLengthHandler builds the content length of a request based on the body,
-// or will use the HTTPRequest.Header's "Content-Length" if defined. If unable
-// to determine request body length and no "Content-Length" was specified it will panic.
+// or will use the HTTPRequest.Header's "Content-Length" if defined. If the body
+// cannot be seeked, e.g. a body set with SetStreamingBody, and no "Content-Length"
+// was specified the body will be sent with chunked transfer encoding. If seeking
+// the body fails the request fails with an UnknownBodyLengthErrCode error.
 //
 // The Content-Length will only be aded to the request if the length of the body
 // is greater than 0. If the body is empty or the current `Content-Length`
@@ -41,13 +43,20 @@ var BuildContentLengthHandler = request.NamedHandler{
 				length = 0
 			case lener:
 				length = int64(body.Len())
-			case io.Seeker:
-				r.BodyStart, _ = body.Seek(0, 1)
-				end, _ := body.Seek(0, 2)
-				body.Seek(r.BodyStart, 0) // make sure to seek back to original location
-				length = end - r.BodyStart
 			default:
-				panic("Cannot get length of body, must provide `ContentLength`")
+				if !r.IsBodySeekable() {
+					// The length of the body is unknown, send it chunked.
+					r.HTTPRequest.ContentLength = -1
+					r.HTTPRequest.Header.Del("Content-Length")
+					return
+				}
+
+				var err error
+				if length, err = seekerLen(r); err != nil {
+					r.Error = awserr.New(request.UnknownBodyLengthErrCode,
+						"unable to determine request body length, use SetStreamingBody for bodies which cannot be seeked", err)
+					return
+				}
 			}
 		}
 
@@ -61,6 +70,61 @@ var BuildContentLengthHandler = request.NamedHandler{
 	},
 }
 
+// seekerLen returns the length of the request's body from its current
+// position, which is recorded as the request's BodyStart.
+func seekerLen(r *request.Request) (int64, error) {
+	start, err := r.Body.Seek(0, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := r.Body.Seek(0, 2)
+	if err != nil {
+		return 0, err
+	}
+
+	// make sure to seek back to original location
+	if _, err := r.Body.Seek(start, 0); err != nil {
+		return 0, err
+	}
+
+	r.BodyStart = start
+	return end - start, nil
+}
+
+// DefaultExpect100ContinueThreshold is the default size of the request body,
+// in bytes, above which the Expect: 100-continue header is added.
+const DefaultExpect100ContinueThreshold int64 = 2 * 1024 * 1024
+
+// Expect100ContinueHandler adds the `Expect: 100-continue` header to requests
+// with a body larger than the Config's Expect100ContinueThreshold, or of
+// unknown length. The HTTP client then waits for the service to accept the
+// request before sending the body, so a rejected request is not transmitted
+// in full. Not added if the Config's Disable100Continue is set.
+//
+// Must be run after BuildContentLengthHandler, as the length of the body is
+// not known until then.
+var Expect100ContinueHandler = request.NamedHandler{
+	Name: "core.Expect100ContinueHandler",
+	Fn: func(r *request.Request) {
+		if service.BoolValue(r.Config.Disable100Continue) || r.ExpireTime != 0 {
+			return
+		}
+
+		threshold := DefaultExpect100ContinueThreshold
+		if r.Config.Expect100ContinueThreshold != nil {
+			threshold = *r.Config.Expect100ContinueThreshold
+		}
+
+		length := r.HTTPRequest.ContentLength
+		if length == 0 || (length > 0 && length <= threshold) {
+			return
+		}
+
+		r.HTTPRequest.Header.Set("Expect", "100-continue")
+	},
+}
+
 // SDKVersionUserAgentHandler is a request handler for adding the SDK Version to the user agent.
 var SDKVersionUserAgentHandler = request.NamedHandler{
 	Name: "core.SDKVersionUserAgentHandler",
@@ -112,6 +176,8 @@ var SendHandler = request.NamedHandler{
 }
 
 // ValidateResponseHandler is a request handler to validate service response.
+// A response with a 5xx status code fails with an awserr.ExtendedRequestFailure
+// including the request's RequestID and HostID.
 var ValidateResponseHandler = request.NamedHandler{
 	Name: "core.ValidateResponseHandler",
 	Fn: func(r *request.Request) {
@@ -122,7 +188,13 @@ var ValidateResponseHandler = request.NamedHandler{
 				msg = "unknown error"
 			}
 
-			r.Error = awserr.New("UnknownError", msg, nil)
+			err := awserr.New("UnknownError", msg, nil)
+			if r.HTTPResponse.StatusCode == 0 {
+				r.Error = err
+				return
+			}
+
+			r.Error = awserr.NewExtendedRequestFailure(err, r.HTTPResponse.StatusCode, r.RequestID, r.HostID)
 		}
 	},
 }
@@ -134,13 +206,19 @@ var AfterRetryHandler = request.NamedHandler{
 	Fn: func(r *request.Request) {
 		// If one of the other handlers already set the retry state
 		// we don't want to override it based on the service's state
-		if r.Retryable == nil {
-			r.Retryable = service.Bool(r.ShouldRetry(r))
-		}
+		r.ResolveRetryable()
 
 		if r.WillRetry() {
 			r.RetryDelay = r.RetryRules(r)
-			r.Config.SleepDelay(r.RetryDelay)
+
+			if sleepFn := r.Config.SleepDelay; sleepFn != nil {
+				// Support SleepDelay for backwards compatibility and testing
+				sleepFn(r.RetryDelay)
+			} else if err := request.SleepWithContext(r.Context(), r.RetryDelay); err != nil {
+				r.Error = awserr.New(request.CanceledErrCode, "request context canceled", err)
+				r.Retryable = service.Bool(false)
+				return
+			}
 
 			// when the expired token exception occurs the credentials
 			// need to be expired locally so that the next request to
@@ -149,12 +227,55 @@ var AfterRetryHandler = request.NamedHandler{
 				r.Config.Credentials.Expire()
 			}
 
+			// when the clock skew error occurs the signature must be
+			// dropped so that the request will be resigned with the
+			// clock offset learned from the service's response.
+			if r.IsErrorClockSkew() {
+				r.ClockSkewRetried = true
+				r.HTTPRequest.Header.Del("Authorization")
+			}
+
 			r.RetryCount++
 			r.Error = nil
 		}
 	},
 }
 
+// minClockSkew is the smallest offset between the service's clock and the
+// local clock which is corrected. The Date header has a resolution of a
+// second, and smaller offsets are within the latency of the response.
+const minClockSkew = 5 * time.Second
+
+// ClockSkewHandler is a request handler to compute the offset between the
+// service's clock and the local clock from the response's Date header. The
+// offset is stored per client endpoint and applied when signing requests.
+// Offsets smaller than a few seconds clear the endpoint's stored offset.
+var ClockSkewHandler = request.NamedHandler{
+	Name: "core.ClockSkewHandler",
+	Fn: func(r *request.Request) {
+		if r.HTTPResponse == nil {
+			return
+		}
+
+		date := r.HTTPResponse.Header.Get("Date")
+		if date == "" {
+			return
+		}
+
+		serverTime, err := http.ParseTime(date)
+		if err != nil {
+			return
+		}
+
+		skew := serverTime.Sub(time.Now())
+		if skew > -minClockSkew && skew < minClockSkew {
+			skew = 0
+		}
+
+		request.SetClockSkew(r.ClientInfo.Endpoint, skew)
+	},
+}
+
 // ValidateEndpointHandler is a request handler to validate a request had the
 // appropriate Region and Endpoint set. Will set r.Error if the endpoint or
 // region is not valid.
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/golib/assert"
 	"github.com/golib/aws/service"
@@ -16,6 +17,7 @@ import (
 	"github.com/golib/aws/service/corehandlers"
 	"github.com/golib/aws/service/credentials"
 	"github.com/golib/aws/service/request"
+	"github.com/golib/aws/service/signer/v4"
 )
 
 func TestValidateEndpointHandler(t *testing.T) {
@@ -92,6 +94,81 @@ func TestAfterRetryRefreshCreds(t *testing.T) {
 	assert.True(t, credProvider.retrieveCalled)
 }
 
+func TestClockSkewHandler(t *testing.T) {
+	svc := awstesting.NewClient(&service.Config{Endpoint: service.String("http://skew-endpoint")})
+	svc.Handlers.Clear()
+	svc.Handlers.UnmarshalMeta.PushBackNamed(corehandlers.ClockSkewHandler)
+	defer request.SetClockSkew(svc.ClientInfo.Endpoint, 0)
+
+	serverTime := time.Now().Add(time.Hour)
+	svc.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Date": []string{serverTime.UTC().Format(http.TimeFormat)}},
+			Body:       ioutil.NopCloser(bytes.NewBuffer([]byte{})),
+		}
+	})
+
+	req := svc.NewRequest(&request.Operation{Name: "Operation"}, nil, nil)
+	assert.NoError(t, req.Send())
+
+	skew := request.ClockSkew(svc.ClientInfo.Endpoint)
+	assert.True(t, skew > 59*time.Minute && skew <= time.Hour, "unexpected skew %v", skew)
+}
+
+func TestClockSkewHandlerSmallOffset(t *testing.T) {
+	svc := awstesting.NewClient(&service.Config{Endpoint: service.String("http://small-skew-endpoint")})
+	svc.Handlers.Clear()
+	svc.Handlers.UnmarshalMeta.PushBackNamed(corehandlers.ClockSkewHandler)
+	defer request.SetClockSkew(svc.ClientInfo.Endpoint, 0)
+
+	svc.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Date": []string{time.Now().Add(time.Second).UTC().Format(http.TimeFormat)}},
+			Body:       ioutil.NopCloser(bytes.NewBuffer([]byte{})),
+		}
+	})
+
+	// An offset within the Date header's resolution is not stored.
+	req := svc.NewRequest(&request.Operation{Name: "Operation"}, nil, nil)
+	assert.NoError(t, req.Send())
+	assert.Equal(t, time.Duration(0), request.ClockSkew(svc.ClientInfo.Endpoint))
+
+	// It clears an offset stored before the clocks were in sync.
+	request.SetClockSkew(svc.ClientInfo.Endpoint, time.Hour)
+
+	req = svc.NewRequest(&request.Operation{Name: "Operation"}, nil, nil)
+	assert.NoError(t, req.Send())
+	assert.Equal(t, time.Duration(0), request.ClockSkew(svc.ClientInfo.Endpoint))
+}
+
+func TestAfterRetryClockSkewRetriesOnce(t *testing.T) {
+	svc := awstesting.NewClient(&service.Config{MaxRetries: service.Int(3)})
+	svc.Handlers.Clear()
+
+	var signatures []string
+	svc.Handlers.Sign.PushBack(func(r *request.Request) {
+		// record the previous signature, which must be dropped before resigning
+		signatures = append(signatures, r.HTTPRequest.Header.Get("Authorization"))
+		r.HTTPRequest.Header.Set("Authorization", "signed")
+	})
+	svc.Handlers.ValidateResponse.PushBack(func(r *request.Request) {
+		r.Error = awserr.New("RequestTimeTooSkewed", "", nil)
+		r.HTTPResponse = &http.Response{StatusCode: 403, Body: ioutil.NopCloser(bytes.NewBuffer([]byte{}))}
+	})
+	svc.Handlers.AfterRetry.PushBackNamed(corehandlers.AfterRetryHandler)
+
+	req := svc.NewRequest(&request.Operation{Name: "Operation"}, nil, nil)
+	err := req.Send()
+
+	assert.Error(t, err)
+	assert.Equal(t, "RequestTimeTooSkewed", err.(awserr.Error).Code())
+	assert.Equal(t, 1, req.RetryCount)
+	assert.True(t, req.ClockSkewRetried)
+	assert.Equal(t, []string{"", ""}, signatures)
+}
+
 type testSendHandlerTransport struct{}
 
 func (t *testSendHandlerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
@@ -136,3 +213,72 @@ func setupContentLengthTestServer(t *testing.T, hasContentLength bool, contentLe
 
 	return server
 }
+
+func TestExpect100ContinueHandler(t *testing.T) {
+	cases := []struct {
+		cfg    *service.Config
+		body   string
+		length int64
+		expect string
+	}{
+		{cfg: service.NewConfig().WithExpect100ContinueThreshold(4), body: "hello", expect: "100-continue"},
+		{cfg: service.NewConfig().WithExpect100ContinueThreshold(5), body: "hello"},
+		{cfg: service.NewConfig(), body: "hello"},
+		{cfg: service.NewConfig(), body: ""},
+		{cfg: service.NewConfig().WithExpect100ContinueThreshold(4).WithDisable100Continue(true), body: "hello"},
+		{cfg: service.NewConfig(), body: "hello", length: -1, expect: "100-continue"},
+	}
+
+	for i, c := range cases {
+		svc := awstesting.NewClient(c.cfg)
+		svc.Handlers.Clear()
+		svc.Handlers.Sign.PushBackNamed(corehandlers.BuildContentLengthHandler)
+		svc.Handlers.Sign.PushBackNamed(corehandlers.Expect100ContinueHandler)
+
+		req := svc.NewRequest(&request.Operation{Name: "Operation", HTTPMethod: "PUT"}, nil, nil)
+		if c.length < 0 {
+			req.SetStreamingBody(ioutil.NopCloser(bytes.NewReader([]byte(c.body))), c.length)
+		} else {
+			req.SetStringBody(c.body)
+		}
+
+		assert.NoError(t, req.Sign(), "case %d", i)
+		assert.Equal(t, c.expect, req.HTTPRequest.Header.Get("Expect"), "case %d", i)
+	}
+}
+
+func TestExpect100ContinueRetryAfterEarlyRejection(t *testing.T) {
+	body := bytes.Repeat([]byte("0123456789"), 1024*1024)
+
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, "100-continue", r.Header.Get("Expect"))
+
+		if attempts == 1 {
+			// Reject the request without reading the body.
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(body, b), "expect the full body to be sent on retry")
+	}))
+	defer server.Close()
+
+	svc := awstesting.NewClient(service.NewConfig().
+		WithRegion("us-west-2").
+		WithCredentials(credentials.NewStaticCredentials("AKID", "SECRET", "")).
+		WithMaxRetries(1).
+		WithSleepDelay(func(time.Duration) {}))
+	svc.ClientInfo.Endpoint = server.URL
+	svc.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+
+	req := svc.NewRequest(&request.Operation{Name: "Operation", HTTPMethod: "PUT", HTTPPath: "/"}, nil, nil)
+	req.SetBufferBody(body)
+
+	assert.NoError(t, req.Send())
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 1, req.RetryCount)
+}
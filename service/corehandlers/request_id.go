@@ -0,0 +1,98 @@
+package corehandlers
+
+import (
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/request"
+)
+
+// DefaultRequestIDHeaders are the response headers the request ID is read
+// from if the Config's RequestIDHeaders is not set, in order of preference.
+var DefaultRequestIDHeaders = []string{
+	"X-Aws-Request-Id",
+	"X-Amzn-Requestid",
+	"X-Amz-Request-Id",
+}
+
+// DefaultHostIDHeaders are the response headers the extended request ID is
+// read from if the Config's HostIDHeaders is not set, in order of preference.
+var DefaultHostIDHeaders = []string{
+	"X-Aws-Id-2",
+	"X-Amz-Id-2",
+}
+
+// RequestIDHandler is a response handler reading the request ID, and the
+// extended request ID identifying the host which handled the request, from
+// the response headers into the request's RequestID and HostID. The headers
+// are read from the Config's RequestIDHeaders and HostIDHeaders, or the
+// DefaultRequestIDHeaders and DefaultHostIDHeaders if not set.
+//
+// The IDs are logged if debug logging is enabled.
+var RequestIDHandler = request.NamedHandler{
+	Name: "core.RequestIDHandler",
+	Fn: func(r *request.Request) {
+		if r.HTTPResponse == nil || r.HTTPResponse.Header == nil {
+			return
+		}
+
+		requestIDHeaders := r.Config.RequestIDHeaders
+		if requestIDHeaders == nil {
+			requestIDHeaders = DefaultRequestIDHeaders
+		}
+		hostIDHeaders := r.Config.HostIDHeaders
+		if hostIDHeaders == nil {
+			hostIDHeaders = DefaultHostIDHeaders
+		}
+
+		// The IDs of a previous attempt's response are not kept.
+		r.RequestID = firstHeader(r, requestIDHeaders)
+		r.HostID = firstHeader(r, hostIDHeaders)
+
+		if (r.RequestID != "" || r.HostID != "") && r.Config.LogLevel.AtLeast(service.LogDebug) {
+			r.Config.LogFields(service.SeverityDebug, "Response IDs", r.LogContext()...)
+		}
+	},
+}
+
+// RequestFailureIDHandler is a handler run once an attempt completes, adding
+// the request's RequestID and HostID to a RequestFailure error without them.
+// The error is replaced with an awserr.ExtendedRequestFailure, with the same
+// code, message and status code.
+var RequestFailureIDHandler = request.NamedHandler{
+	Name: "core.RequestFailureIDHandler",
+	Fn: func(r *request.Request) {
+		reqErr, ok := r.Error.(awserr.RequestFailure)
+		if !ok {
+			return
+		}
+
+		requestID, hostID := reqErr.RequestID(), ""
+		if extErr, ok := reqErr.(awserr.ExtendedRequestFailure); ok {
+			hostID = extErr.HostID()
+		}
+
+		if (requestID != "" || r.RequestID == "") && (hostID != "" || r.HostID == "") {
+			// The error has the IDs, or there are none to add.
+			return
+		}
+		if requestID == "" {
+			requestID = r.RequestID
+		}
+		if hostID == "" {
+			hostID = r.HostID
+		}
+
+		r.Error = awserr.NewExtendedRequestFailure(reqErr, reqErr.StatusCode(), requestID, hostID)
+	},
+}
+
+// firstHeader returns the value of the first of the response headers set.
+func firstHeader(r *request.Request, headers []string) string {
+	for _, h := range headers {
+		if v := r.HTTPResponse.Header.Get(h); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
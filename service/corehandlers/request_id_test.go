@@ -0,0 +1,130 @@
+package corehandlers_test
+
+import (
+	"testing"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awstesting/mock"
+	"github.com/golib/aws/service/request"
+)
+
+func newRequestIDRequest(server *mock.Server, cfgs ...*service.Config) *request.Request {
+	svc := server.NewClient(cfgs...)
+
+	req := svc.NewRequest(&request.Operation{Name: "ListTables", HTTPMethod: "POST", HTTPPath: "/"}, nil, nil)
+	req.HTTPRequest.Header.Set("X-Aws-Target", "Mock.ListTables")
+	req.Handlers.Retry.PushBack(func(r *request.Request) {
+		r.RetryDelay = 0
+	})
+
+	return req
+}
+
+func TestRequestIDHandler(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(
+		mock.JSONResponse(200, `{}`).
+			WithHeader("X-Amz-Request-Id", "amz").
+			WithHeader("X-Amzn-Requestid", "amzn").
+			WithHeader("X-Amz-Id-2", "host"),
+	)
+
+	req := newRequestIDRequest(server)
+	assert.Nil(t, req.Send())
+	assert.Equal(t, "amzn", req.RequestID)
+	assert.Equal(t, "host", req.HostID)
+}
+
+func TestRequestIDHandlerConfiguredHeaders(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(
+		mock.JSONResponse(200, `{}`).
+			WithHeader("X-Aws-Request-Id", "default").
+			WithHeader("X-Custom-Request-Id", "custom").
+			WithHeader("X-Custom-Host-Id", "host"),
+	)
+
+	cfg := service.NewConfig().
+		WithRequestIDHeaders("X-Custom-Request-Id").
+		WithHostIDHeaders("X-Custom-Host-Id")
+
+	req := newRequestIDRequest(server, cfg)
+	assert.Nil(t, req.Send())
+	assert.Equal(t, "custom", req.RequestID)
+	assert.Equal(t, "host", req.HostID)
+}
+
+func TestRequestIDHandlerRetry(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(
+		mock.ServerErrorResponse().
+			WithHeader("X-Aws-Request-Id", "first").
+			WithHeader("X-Aws-Id-2", "first-host"),
+		mock.JSONResponse(200, `{}`).WithHeader("X-Aws-Request-Id", "second"),
+	)
+
+	req := newRequestIDRequest(server, service.NewConfig().WithMaxRetries(1))
+	assert.Nil(t, req.Send())
+	assert.Equal(t, 1, req.RetryCount)
+	assert.Equal(t, "second", req.RequestID)
+	assert.Empty(t, req.HostID)
+}
+
+func TestRequestIDInRequestFailure(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(
+		mock.ServerErrorResponse().
+			WithHeader("X-Aws-Request-Id", "abc").
+			WithHeader("X-Aws-Id-2", "host"),
+	)
+
+	req := newRequestIDRequest(server, service.NewConfig().WithMaxRetries(0))
+	err := req.Send()
+	assert.NotNil(t, err)
+
+	reqErr, ok := err.(awserr.ExtendedRequestFailure)
+	if assert.True(t, ok, "expected ExtendedRequestFailure, got %T", err) {
+		assert.Equal(t, 500, reqErr.StatusCode())
+		assert.Equal(t, "abc", reqErr.RequestID())
+		assert.Equal(t, "host", reqErr.HostID())
+		assert.Contains(t, reqErr.Error(), "request id: abc, host id: host")
+	}
+}
+
+func TestRequestFailureIDHandler(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(
+		mock.JSONResponse(400, `{}`).
+			WithHeader("X-Aws-Request-Id", "abc").
+			WithHeader("X-Aws-Id-2", "host"),
+	)
+
+	req := newRequestIDRequest(server, service.NewConfig().WithMaxRetries(0))
+	req.Handlers.ValidateResponse.PushBack(func(r *request.Request) {
+		if r.HTTPResponse.StatusCode >= 300 {
+			r.Error = awserr.NewRequestFailure(awserr.New("ValidationException", "invalid", nil), r.HTTPResponse.StatusCode, "")
+		}
+	})
+
+	err := req.Send()
+	reqErr, ok := err.(awserr.ExtendedRequestFailure)
+	if assert.True(t, ok, "expected ExtendedRequestFailure, got %T", err) {
+		assert.Equal(t, "ValidationException", reqErr.Code())
+		assert.Equal(t, "invalid", reqErr.Message())
+		assert.Equal(t, 400, reqErr.StatusCode())
+		assert.Equal(t, "abc", reqErr.RequestID())
+		assert.Equal(t, "host", reqErr.HostID())
+	}
+}
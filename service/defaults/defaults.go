@@ -10,7 +10,6 @@ package defaults
 import (
 	"net/http"
 	"os"
-	"time"
 
 	"github.com/golib/aws/service"
 	"github.com/golib/aws/service/corehandlers"
@@ -50,8 +49,7 @@ func Config() *service.Config {
 		WithHTTPClient(http.DefaultClient).
 		WithMaxRetries(service.UseServiceDefaultRetries).
 		WithLogger(service.NewDefaultLogger()).
-		WithLogLevel(service.LogOff).
-		WithSleepDelay(time.Sleep)
+		WithLogLevel(service.LogOff)
 }
 
 // Handlers returns the default request handlers.
@@ -66,10 +64,18 @@ func Handlers() request.Handlers {
 	handlers.Validate.AfterEachFn = request.HandlerListStopOnError
 	handlers.Build.PushBackNamed(corehandlers.SDKVersionUserAgentHandler)
 	handlers.Build.AfterEachFn = request.HandlerListStopOnError
+	handlers.Sign.PushBackNamed(corehandlers.CompressRequestHandler)
 	handlers.Sign.PushBackNamed(corehandlers.BuildContentLengthHandler)
+	handlers.Sign.PushBackNamed(corehandlers.Expect100ContinueHandler)
+	handlers.Sign.PushBackNamed(corehandlers.ContentMD5Handler)
+	handlers.Sign.PushBackNamed(corehandlers.RequestChecksumHandler)
 	handlers.Send.PushBackNamed(corehandlers.SendHandler)
+	handlers.UnmarshalMeta.PushBackNamed(corehandlers.RequestIDHandler)
+	handlers.UnmarshalMeta.PushBackNamed(corehandlers.ClockSkewHandler)
 	handlers.AfterRetry.PushBackNamed(corehandlers.AfterRetryHandler)
 	handlers.ValidateResponse.PushBackNamed(corehandlers.ValidateResponseHandler)
+	handlers.ValidateResponse.PushBackNamed(corehandlers.ValidateResponseChecksumHandler)
+	handlers.CompleteAttempt.PushBackNamed(corehandlers.RequestFailureIDHandler)
 
 	return handlers
 }
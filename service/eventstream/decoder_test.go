@@ -0,0 +1,117 @@
+package eventstream
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func TestEncodeDecodeMessage(t *testing.T) {
+	msg := Message{
+		Headers: Headers{
+			{Name: ":true", Value: BoolValue(true)},
+			{Name: ":false", Value: BoolValue(false)},
+			{Name: ":int8", Value: Int8Value(-8)},
+			{Name: ":int16", Value: Int16Value(-16)},
+			{Name: ":int32", Value: Int32Value(-32)},
+			{Name: ":int64", Value: Int64Value(-64)},
+			{Name: ":bytes", Value: BytesValue([]byte{1, 2, 3})},
+			{Name: ":string", Value: StringValue("value")},
+			{Name: ":date", Value: TimestampValue(time.Unix(1369353600, 0))},
+			{Name: ":uuid", Value: UUIDValue{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}},
+		},
+		Payload: []byte(`{"foo":"bar"}`),
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, NewEncoder(&buf).Encode(msg))
+
+	decoded, err := NewDecoder(&buf).Decode(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, msg.Payload, decoded.Payload)
+	assert.Len(t, decoded.Headers, len(msg.Headers))
+
+	for _, h := range msg.Headers {
+		v := decoded.Headers.Get(h.Name)
+		if assert.NotNil(t, v, h.Name) {
+			assert.Equal(t, h.Value.String(), v.String(), h.Name)
+		}
+	}
+}
+
+func TestEncodeEmptyMessage(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, NewEncoder(&buf).Encode(Message{}))
+	assert.Equal(t, minMsgLen, buf.Len())
+
+	decoded, err := NewDecoder(&buf).Decode(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, decoded.Headers)
+	assert.Empty(t, decoded.Payload)
+}
+
+func TestDecodeMultipleMessages(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	assert.NoError(t, enc.Encode(Message{Payload: []byte("first")}))
+	assert.NoError(t, enc.Encode(Message{Payload: []byte("second")}))
+
+	dec := NewDecoder(&buf)
+	payloadBuf := make([]byte, 0, 1024)
+
+	m, err := dec.Decode(payloadBuf)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", string(m.Payload))
+
+	m, err = dec.Decode(payloadBuf)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", string(m.Payload))
+}
+
+func TestDecodeChecksumMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, NewEncoder(&buf).Encode(Message{Payload: []byte("payload")}))
+
+	b := buf.Bytes()
+	b[len(b)-5] ^= 0xff // corrupt the payload
+
+	_, err := NewDecoder(bytes.NewReader(b)).Decode(nil)
+	assert.Error(t, err)
+	_, ok := err.(ChecksumError)
+	assert.True(t, ok, "expect checksum error, got %v", err)
+}
+
+func TestDecodePreludeChecksumMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, NewEncoder(&buf).Encode(Message{Payload: []byte("payload")}))
+
+	b := buf.Bytes()
+	b[preludeLen] ^= 0xff // corrupt the prelude checksum
+
+	_, err := NewDecoder(bytes.NewReader(b)).Decode(nil)
+	_, ok := err.(ChecksumError)
+	assert.True(t, ok, "expect checksum error, got %v", err)
+}
+
+func TestHeaders(t *testing.T) {
+	hs := Headers{}
+	hs.Set("a", StringValue("1"))
+	hs.Set("b", StringValue("2"))
+	hs.Set("a", StringValue("3"))
+
+	assert.Len(t, hs, 2)
+	assert.Equal(t, StringValue("3"), hs.Get("a"))
+
+	hs.Del("a")
+	assert.Len(t, hs, 1)
+	assert.Nil(t, hs.Get("a"))
+}
+
+func TestEncodeHeaderNameTooLong(t *testing.T) {
+	var buf bytes.Buffer
+	err := EncodeHeaders(&buf, Headers{{Name: string(make([]byte, maxHeaderNameLen+1)), Value: BoolValue(true)}})
+	_, ok := err.(LengthError)
+	assert.True(t, ok, "expect length error, got %v", err)
+}
@@ -0,0 +1,60 @@
+package eventstream
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Encoder provides EventStream message encoding.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder initializes and returns an Encoder to encode EventStream
+// messages to the io.Writer provided.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: w,
+	}
+}
+
+// Encode encodes a single EventStream message to the io.Writer the Encoder
+// was created with. An error is returned if writing the message fails.
+func (e *Encoder) Encode(msg Message) error {
+	raw, err := msg.rawMessage()
+	if err != nil {
+		return err
+	}
+
+	err = binaryWriteFields(e.w, binary.BigEndian,
+		raw.Length,
+		raw.HeadersLen,
+		raw.PreludeCRC,
+	)
+	if err != nil {
+		return err
+	}
+
+	if raw.HeadersLen > 0 {
+		if _, err := e.w.Write(raw.Headers); err != nil {
+			return err
+		}
+	}
+
+	if len(raw.Payload) > 0 {
+		if _, err := e.w.Write(raw.Payload); err != nil {
+			return err
+		}
+	}
+
+	return binary.Write(e.w, binary.BigEndian, raw.CRC)
+}
+
+func binaryWriteFields(w io.Writer, order binary.ByteOrder, vs ...interface{}) error {
+	for _, v := range vs {
+		if err := binary.Write(w, order, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package eventstream
+
+import (
+	"fmt"
+
+	"github.com/golib/aws/service/awserr"
+)
+
+// LengthError provides the error for items being larger than a maximum length.
+type LengthError struct {
+	Part  string
+	Want  int
+	Have  int
+	Value interface{}
+}
+
+// Code is the short id of the error.
+func (e LengthError) Code() string {
+	return "LengthError"
+}
+
+// Message is the description of the error.
+func (e LengthError) Message() string {
+	return fmt.Sprintf("%s length invalid, %d/%d, %v", e.Part, e.Want, e.Have, e.Value)
+}
+
+// OrigErr is the underlying error that caused this error.
+func (e LengthError) OrigErr() error {
+	return nil
+}
+
+// Error satisfies the error interface.
+func (e LengthError) Error() string {
+	return awserr.SprintError(e.Code(), e.Message(), "", nil)
+}
+
+// ChecksumError provides the error for message checksum invalidation errors.
+type ChecksumError struct {
+	Want uint32
+	Have uint32
+}
+
+// Code is the short id of the error.
+func (e ChecksumError) Code() string {
+	return "ChecksumError"
+}
+
+// Message is the description of the error.
+func (e ChecksumError) Message() string {
+	return fmt.Sprintf("message checksum mismatch, expect %08x, got %08x", e.Want, e.Have)
+}
+
+// OrigErr is the underlying error that caused this error.
+func (e ChecksumError) OrigErr() error {
+	return nil
+}
+
+// Error satisfies the error interface.
+func (e ChecksumError) Error() string {
+	return awserr.SprintError(e.Code(), e.Message(), "", nil)
+}
@@ -0,0 +1,126 @@
+package eventstream
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+const maxHeaderNameLen = 255
+
+// Header is a single EventStream Key Value header pair.
+type Header struct {
+	Name  string
+	Value Value
+}
+
+// Headers are a collection of EventStream header values.
+type Headers []Header
+
+// Set associates the name with a value. If the header name already exists in
+// the Headers the value will be replaced with the new one.
+func (hs *Headers) Set(name string, value Value) {
+	var i int
+	for ; i < len(*hs); i++ {
+		if (*hs)[i].Name == name {
+			(*hs)[i].Value = value
+			return
+		}
+	}
+
+	*hs = append(*hs, Header{
+		Name: name, Value: value,
+	})
+}
+
+// Get returns the Value associated with the header. Nil is returned if the
+// value does not exist.
+func (hs Headers) Get(name string) Value {
+	for i := 0; i < len(hs); i++ {
+		if h := hs[i]; h.Name == name {
+			return h.Value
+		}
+	}
+	return nil
+}
+
+// Del deletes the value in the Headers if it exists.
+func (hs *Headers) Del(name string) {
+	for i := 0; i < len(*hs); i++ {
+		if (*hs)[i].Name == name {
+			copy((*hs)[i:], (*hs)[i+1:])
+			(*hs) = (*hs)[:len(*hs)-1]
+		}
+	}
+}
+
+// EncodeHeaders writes the header values to the writer in the EventStream
+// binary format. The encoded headers are what is signed when signing
+// EventStream messages.
+func EncodeHeaders(w io.Writer, headers Headers) error {
+	for _, h := range headers {
+		if err := encodeHeaderName(w, h.Name); err != nil {
+			return err
+		}
+
+		if err := h.Value.encode(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeHeaderName(w io.Writer, name string) error {
+	if len(name) > maxHeaderNameLen {
+		return LengthError{
+			Part: "header name",
+			Want: maxHeaderNameLen, Have: len(name),
+			Value: name,
+		}
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint8(len(name))); err != nil {
+		return err
+	}
+
+	_, err := w.Write([]byte(name))
+	return err
+}
+
+func decodeHeaders(r io.Reader) (Headers, error) {
+	hs := Headers{}
+
+	for {
+		name, err := decodeHeaderName(r)
+		if err != nil {
+			if err == io.EOF {
+				// EOF while getting header name means no more headers
+				break
+			}
+			return nil, err
+		}
+
+		value, err := decodeHeaderValue(r)
+		if err != nil {
+			return nil, err
+		}
+
+		hs.Set(name, value)
+	}
+
+	return hs, nil
+}
+
+func decodeHeaderName(r io.Reader) (string, error) {
+	var n uint8
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return "", err
+	}
+
+	name := make([]byte, n)
+	if _, err := io.ReadFull(r, name); err != nil {
+		return "", err
+	}
+
+	return string(name), nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// LogRedactedValue is the value sensitive headers and query parameters are
+// masked with in debug logs.
+const LogRedactedValue = "REDACTED"
+
+// DefaultLogMaxBodyBytes is the default maximum number of bytes of request
+// and response bodies logged with LogDebugWithHTTPBody.
+const DefaultLogMaxBodyBytes = 4096
+
+// DefaultLogRedactedHeaders are the headers whose values are masked in debug
+// logs by default.
+var DefaultLogRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Aws-Security-Token",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// DefaultLogRedactedQuery are the query parameters whose values are masked
+// in debug logs by default, such as the credentials of presigned URLs.
+var DefaultLogRedactedQuery = []string{
+	"X-Aws-Security-Token",
+	"X-Aws-Credential",
+	"X-Aws-Signature",
+}
+
+// A LogRedactor masks sensitive headers and query parameters, and truncates
+// bodies, in debug logs. The zero value masks nothing, and does not truncate
+// bodies.
+type LogRedactor struct {
+	// Headers are the names of the headers whose values are masked,
+	// matched case-insensitively.
+	Headers []string
+
+	// Query are the names of the query parameters whose values are masked.
+	Query []string
+
+	// MaxBodyBytes is the maximum number of bytes of a body logged. Bodies
+	// are not truncated if zero or negative.
+	MaxBodyBytes int
+}
+
+// LogRedactor returns the LogRedactor of the config's debug logs. The
+// DefaultLogRedactedHeaders and DefaultLogRedactedQuery are masked, in
+// addition to the config's LogRedactedHeaders and LogRedactedQuery. Nothing
+// is masked or truncated if the config's DisableLogRedaction is set.
+func (c *Config) LogRedactor() *LogRedactor {
+	if BoolValue(c.DisableLogRedaction) {
+		return &LogRedactor{}
+	}
+
+	maxBodyBytes := DefaultLogMaxBodyBytes
+	if c.LogMaxBodyBytes != nil {
+		maxBodyBytes = *c.LogMaxBodyBytes
+	}
+
+	return &LogRedactor{
+		Headers:      append(append([]string{}, DefaultLogRedactedHeaders...), c.LogRedactedHeaders...),
+		Query:        append(append([]string{}, DefaultLogRedactedQuery...), c.LogRedactedQuery...),
+		MaxBodyBytes: maxBodyBytes,
+	}
+}
+
+// IsRedactedHeader returns whether the values of the header are masked.
+func (r *LogRedactor) IsRedactedHeader(name string) bool {
+	for _, h := range r.Headers {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsRedactedQuery returns whether the values of the query parameter are
+// masked.
+func (r *LogRedactor) IsRedactedQuery(name string) bool {
+	for _, q := range r.Query {
+		if q == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Header returns a copy of the header with the values of masked headers
+// replaced by LogRedactedValue.
+func (r *LogRedactor) Header(header http.Header) http.Header {
+	h := make(http.Header, len(header))
+	for k, v := range header {
+		if r.IsRedactedHeader(k) {
+			h[k] = []string{LogRedactedValue}
+			continue
+		}
+		h[k] = append([]string{}, v...)
+	}
+
+	return h
+}
+
+// URL returns a copy of the URL with the values of masked query parameters
+// replaced by LogRedactedValue.
+func (r *LogRedactor) URL(u *url.URL) *url.URL {
+	redacted := *u
+	if u.RawQuery == "" {
+		return &redacted
+	}
+
+	query := u.Query()
+	for k := range query {
+		if r.IsRedactedQuery(k) {
+			query.Set(k, LogRedactedValue)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+
+	return &redacted
+}
+
+// Body returns the body truncated to MaxBodyBytes, noting the number of
+// bytes truncated.
+func (r *LogRedactor) Body(body string) string {
+	if r.MaxBodyBytes <= 0 || len(body) <= r.MaxBodyBytes {
+		return body
+	}
+
+	return fmt.Sprintf("%s...(%d bytes truncated)", body[:r.MaxBodyBytes], len(body)-r.MaxBodyBytes)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestConfigLogRedactor(t *testing.T) {
+	r := (&Config{}).LogRedactor()
+	assert.Equal(t, DefaultLogRedactedHeaders, r.Headers)
+	assert.Equal(t, DefaultLogRedactedQuery, r.Query)
+	assert.Equal(t, DefaultLogMaxBodyBytes, r.MaxBodyBytes)
+
+	cfg := NewConfig().
+		WithLogRedactedHeaders("X-Api-Key").
+		WithLogRedactedQuery("token").
+		WithLogMaxBodyBytes(-1)
+	r = cfg.LogRedactor()
+	assert.True(t, r.IsRedactedHeader("authorization"))
+	assert.True(t, r.IsRedactedHeader("X-API-KEY"))
+	assert.True(t, r.IsRedactedQuery("token"))
+	assert.True(t, r.IsRedactedQuery("X-Aws-Signature"))
+	assert.False(t, r.IsRedactedHeader("Content-Type"))
+	assert.Equal(t, -1, r.MaxBodyBytes)
+
+	// The defaults are not modified by the config's additions.
+	assert.NotContains(t, DefaultLogRedactedHeaders, "X-Api-Key")
+
+	r = cfg.WithDisableLogRedaction(true).LogRedactor()
+	assert.False(t, r.IsRedactedHeader("Authorization"))
+	assert.Equal(t, 0, r.MaxBodyBytes)
+}
+
+func TestLogRedactorHeader(t *testing.T) {
+	r := (&Config{}).LogRedactor()
+	header := http.Header{
+		"Authorization":        []string{"AWS4-HMAC-SHA256 Credential=AKID/..."},
+		"X-Aws-Security-Token": []string{"SESSION"},
+		"Content-Type":         []string{"application/json"},
+	}
+
+	redacted := r.Header(header)
+	assert.Equal(t, LogRedactedValue, redacted.Get("Authorization"))
+	assert.Equal(t, LogRedactedValue, redacted.Get("X-Aws-Security-Token"))
+	assert.Equal(t, "application/json", redacted.Get("Content-Type"))
+
+	// The header is not modified.
+	assert.Equal(t, "SESSION", header.Get("X-Aws-Security-Token"))
+}
+
+func TestLogRedactorURL(t *testing.T) {
+	r := (&Config{}).LogRedactor()
+	u, _ := url.Parse("https://service.region.amazonaws.com/path?X-Aws-Signature=abc&X-Aws-Credential=AKID%2Fscope&Action=List")
+
+	redacted := r.URL(u)
+	query := redacted.Query()
+	assert.Equal(t, LogRedactedValue, query.Get("X-Aws-Signature"))
+	assert.Equal(t, LogRedactedValue, query.Get("X-Aws-Credential"))
+	assert.Equal(t, "List", query.Get("Action"))
+	assert.Equal(t, "/path", redacted.Path)
+	assert.Contains(t, u.RawQuery, "X-Aws-Signature=abc")
+
+	u, _ = url.Parse("https://service.region.amazonaws.com/path")
+	assert.Equal(t, u.String(), r.URL(u).String())
+}
+
+func TestLogRedactorBody(t *testing.T) {
+	r := &LogRedactor{MaxBodyBytes: 4}
+	assert.Equal(t, "abcd", r.Body("abcd"))
+	assert.Equal(t, "abcd...(2 bytes truncated)", r.Body("abcdef"))
+
+	r.MaxBodyBytes = 0
+	body := strings.Repeat("a", DefaultLogMaxBodyBytes*2)
+	assert.Equal(t, body, r.Body(body))
+}
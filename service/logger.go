@@ -1,8 +1,12 @@
 package service
 
 import (
+	"bytes"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // A LogLevelType defines the level logging should be performed at. Used to instruct
@@ -110,3 +114,81 @@ type defaultLogger struct {
 func (l defaultLogger) Log(args ...interface{}) {
 	l.logger.Println(args...)
 }
+
+// A LogSeverity is the severity of a structured log entry.
+type LogSeverity int
+
+// Severities of structured log entries.
+const (
+	SeverityDebug LogSeverity = iota
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+)
+
+// String returns the name of the severity, e.g. "DEBUG".
+func (s LogSeverity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "DEBUG"
+	case SeverityInfo:
+		return "INFO"
+	case SeverityWarn:
+		return "WARN"
+	case SeverityError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("SEVERITY(%d)", int(s))
+	}
+}
+
+// A StructuredLogger is an interface for the SDK to log entries with a
+// severity, message and key/value fields to, for log pipelines which parse
+// the fields of entries. The fields are alternating keys, which are strings,
+// and values.
+type StructuredLogger interface {
+	LogFields(severity LogSeverity, msg string, keyvals ...interface{})
+}
+
+// NewStructuredLogger returns a StructuredLogger adapting the Logger. Entries
+// are logged on a single line, with the severity, message and fields formatted
+// as key=value pairs. Values with whitespace or quotes are quoted.
+//
+//	DEBUG: Request failed service=DynamoDB operation=GetItem attempt=1
+func NewStructuredLogger(logger Logger) StructuredLogger {
+	return &structuredLogger{logger: logger}
+}
+
+// structuredLogger adapts a Logger to the StructuredLogger interface.
+type structuredLogger struct {
+	logger Logger
+}
+
+// LogFields formats the entry and logs it to the Logger.
+func (l *structuredLogger) LogFields(severity LogSeverity, msg string, keyvals ...interface{}) {
+	var buf bytes.Buffer
+	buf.WriteString(severity.String())
+	buf.WriteString(": ")
+	buf.WriteString(msg)
+
+	for i := 0; i < len(keyvals); i += 2 {
+		var v interface{} = "!MISSING"
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		fmt.Fprintf(&buf, " %v=%s", keyvals[i], formatLogValue(v))
+	}
+
+	l.logger.Log(buf.String())
+}
+
+// formatLogValue returns the value formatted for a log entry, quoted if it
+// contains whitespace or quotes.
+func formatLogValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
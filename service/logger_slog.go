@@ -0,0 +1,43 @@
+//go:build go1.21
+// +build go1.21
+
+package service
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewSlogLogger returns a StructuredLogger logging entries to the log/slog
+// Logger, with their fields as attributes. A nil Logger logs to
+// slog.Default.
+func NewSlogLogger(logger *slog.Logger) StructuredLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &slogLogger{logger: logger}
+}
+
+// slogLogger adapts a log/slog Logger to the StructuredLogger interface.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// LogFields logs the entry at the slog level of the severity.
+func (l *slogLogger) LogFields(severity LogSeverity, msg string, keyvals ...interface{}) {
+	l.logger.Log(context.Background(), slogLevel(severity), msg, keyvals...)
+}
+
+func slogLevel(severity LogSeverity) slog.Level {
+	switch severity {
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarn:
+		return slog.LevelWarn
+	case SeverityError:
+		return slog.LevelError
+	default:
+		return slog.LevelDebug
+	}
+}
@@ -0,0 +1,46 @@
+//go:build go1.21
+// +build go1.21
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	})))
+
+	logger.LogFields(SeverityWarn, "Request failed", "operation", "GetItem", "attempt", 2)
+
+	var entry map[string]interface{}
+	if !assert.Nil(t, json.Unmarshal(buf.Bytes(), &entry)) {
+		return
+	}
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "Request failed", entry["msg"])
+	assert.Equal(t, "GetItem", entry["operation"])
+	assert.Equal(t, float64(2), entry["attempt"])
+}
+
+func TestSlogLoggerLevels(t *testing.T) {
+	cases := map[LogSeverity]slog.Level{
+		SeverityDebug: slog.LevelDebug,
+		SeverityInfo:  slog.LevelInfo,
+		SeverityWarn:  slog.LevelWarn,
+		SeverityError: slog.LevelError,
+	}
+
+	for severity, level := range cases {
+		assert.Equal(t, level, slogLevel(severity), "severity %v", severity)
+	}
+
+	assert.NotNil(t, NewSlogLogger(nil))
+}
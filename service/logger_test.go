@@ -0,0 +1,76 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+type recordedEntry struct {
+	severity LogSeverity
+	msg      string
+	keyvals  []interface{}
+}
+
+type recordingStructuredLogger struct {
+	entries []recordedEntry
+}
+
+func (l *recordingStructuredLogger) LogFields(severity LogSeverity, msg string, keyvals ...interface{}) {
+	l.entries = append(l.entries, recordedEntry{severity, msg, keyvals})
+}
+
+func TestStructuredLoggerAdapter(t *testing.T) {
+	cases := []struct {
+		Severity LogSeverity
+		Msg      string
+		Keyvals  []interface{}
+		Expect   string
+	}{
+		{SeverityDebug, "Request failed", nil, "DEBUG: Request failed"},
+		{SeverityInfo, "Request", []interface{}{"operation", "GetItem", "attempt", 2},
+			"INFO: Request operation=GetItem attempt=2"},
+		{SeverityWarn, "Response", []interface{}{"response", "HTTP/1.1 200 OK\r\n", "empty", ""},
+			`WARN: Response response="HTTP/1.1 200 OK\r\n" empty=""`},
+		{SeverityError, "Odd", []interface{}{"key"}, "ERROR: Odd key=!MISSING"},
+		{LogSeverity(9), "Unknown", nil, "SEVERITY(9): Unknown"},
+	}
+
+	for i, c := range cases {
+		var logged []interface{}
+		logger := NewStructuredLogger(LoggerFunc(func(args ...interface{}) {
+			logged = args
+		}))
+
+		logger.LogFields(c.Severity, c.Msg, c.Keyvals...)
+		assert.Equal(t, []interface{}{c.Expect}, logged, "case %d", i)
+	}
+}
+
+func TestConfigLogFields(t *testing.T) {
+	var logged []interface{}
+	logger := LoggerFunc(func(args ...interface{}) {
+		logged = append(logged, args...)
+	})
+	structured := &recordingStructuredLogger{}
+
+	// Nothing is logged without a logger.
+	(&Config{}).LogFields(SeverityDebug, "msg")
+
+	cfg := NewConfig().WithLogger(logger)
+	cfg.LogFields(SeverityDebug, "msg", "key", "value")
+	assert.Equal(t, []interface{}{"DEBUG: msg key=value"}, logged)
+
+	cfg.WithStructuredLogger(structured)
+	cfg.LogFields(SeverityWarn, "msg", "key", "value")
+	assert.Len(t, logged, 1)
+	if assert.Len(t, structured.entries, 1) {
+		assert.Equal(t, SeverityWarn, structured.entries[0].severity)
+		assert.Equal(t, "msg", structured.entries[0].msg)
+		assert.Equal(t, []interface{}{"key", "value"}, structured.entries[0].keyvals)
+	}
+
+	merged := NewConfig()
+	merged.MergeIn(cfg)
+	assert.Equal(t, structured, merged.StructuredLogger)
+}
@@ -0,0 +1,249 @@
+// Package metrics provides opt-in client-side metrics of service requests.
+// A metric is published for each attempt to send a request, and for each API
+// call once it completes, to a Publisher.
+//
+//	publisher, err := metrics.NewUDPPublisher(metrics.DefaultUDPAddr)
+//	if err != nil {
+//		return err
+//	}
+//
+//	reporter := metrics.New("my-app", publisher)
+//	reporter.AddHandlers(&svc.Handlers)
+//
+// Metrics are published in the format of client-side monitoring, and the
+// UDPPublisher sends them to a client-side monitoring agent on localhost.
+package metrics
+
+import (
+	"time"
+
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/request"
+)
+
+// Types of metrics.
+const (
+	// APICallType is the type of metrics of API calls.
+	APICallType = "ApiCall"
+
+	// APICallAttemptType is the type of metrics of attempts of API calls.
+	APICallAttemptType = "ApiCallAttempt"
+)
+
+// Version is the version of the metric format.
+const Version = 1
+
+// A Metric is a metric of an API call, or an attempt of an API call. Fields
+// which are not known for the metric are nil.
+type Metric struct {
+	ClientID  string `json:"ClientId"`
+	Type      string `json:"Type"`
+	Version   int    `json:"Version"`
+	Timestamp int64  `json:"Timestamp"`
+
+	Service string `json:"Service"`
+	API     string `json:"Api"`
+	Region  string `json:"Region,omitempty"`
+
+	// Fields of ApiCallAttempt metrics.
+	Fqdn                string `json:"Fqdn,omitempty"`
+	UserAgent           string `json:"UserAgent,omitempty"`
+	CredentialProvider  string `json:"CredentialProvider,omitempty"`
+	HTTPStatusCode      *int   `json:"HttpStatusCode,omitempty"`
+	AWSException        string `json:"AwsException,omitempty"`
+	AWSExceptionMessage string `json:"AwsExceptionMessage,omitempty"`
+	SDKException        string `json:"SdkException,omitempty"`
+	SDKExceptionMessage string `json:"SdkExceptionMessage,omitempty"`
+	XAwsRequestID       string `json:"XAwsRequestId,omitempty"`
+	AttemptLatency      *int   `json:"AttemptLatency,omitempty"`
+	RetryReason         string `json:"RetryReason,omitempty"`
+
+	// Fields of ApiCall metrics.
+	AttemptCount             *int   `json:"AttemptCount,omitempty"`
+	Latency                  *int   `json:"Latency,omitempty"`
+	FinalHTTPStatusCode      *int   `json:"FinalHttpStatusCode,omitempty"`
+	FinalAWSException        string `json:"FinalAwsException,omitempty"`
+	FinalAWSExceptionMessage string `json:"FinalAwsExceptionMessage,omitempty"`
+	FinalSDKException        string `json:"FinalSdkException,omitempty"`
+	FinalSDKExceptionMessage string `json:"FinalSdkExceptionMessage,omitempty"`
+	MaxRetriesExceeded       *int   `json:"MaxRetriesExceeded,omitempty"`
+}
+
+// A Publisher publishes metrics. Publish is called from the request's
+// goroutine, and must not block.
+type Publisher interface {
+	Publish(m *Metric) error
+}
+
+// The PublisherFunc type is an adapter to allow the use of ordinary
+// functions as Publishers.
+type PublisherFunc func(m *Metric) error
+
+// Publish calls f(m).
+func (f PublisherFunc) Publish(m *Metric) error {
+	return f(m)
+}
+
+// A Reporter publishes metrics of the requests it's added to.
+type Reporter struct {
+	clientID  string
+	publisher Publisher
+}
+
+// New returns a new Reporter publishing metrics of requests to the
+// publisher, identified by the client ID.
+func New(clientID string, publisher Publisher) *Reporter {
+	return &Reporter{
+		clientID:  clientID,
+		publisher: publisher,
+	}
+}
+
+// AddHandlers injects the reporter's handlers into the handlers. A metric is
+// published as each attempt of a request completes, and as the request
+// completes.
+func (rep *Reporter) AddHandlers(handlers *request.Handlers) {
+	handlers.CompleteAttempt.PushBackNamed(request.NamedHandler{
+		Name: "metrics.APICallAttemptHandler", Fn: rep.apiCallAttemptHandler,
+	})
+	handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "metrics.APICallHandler", Fn: rep.apiCallHandler,
+	})
+}
+
+// newMetric returns a new metric of the type for the request.
+func (rep *Reporter) newMetric(r *request.Request, typ string) *Metric {
+	return &Metric{
+		ClientID:  rep.clientID,
+		Type:      typ,
+		Version:   Version,
+		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
+		Service:   r.ClientInfo.ServiceName,
+		API:       r.Operation.Name,
+		Region:    service.StringValue(r.Config.Region),
+	}
+}
+
+func (rep *Reporter) apiCallAttemptHandler(r *request.Request) {
+	m := rep.newMetric(r, APICallAttemptType)
+	m.AttemptLatency = milliseconds(time.Since(r.AttemptTime))
+	m.XAwsRequestID = r.RequestID
+
+	if r.HTTPRequest != nil {
+		m.Fqdn = r.HTTPRequest.URL.Hostname()
+		m.UserAgent = r.HTTPRequest.Header.Get("User-Agent")
+	}
+	m.CredentialProvider = r.CredentialProvider
+
+	m.HTTPStatusCode = statusCode(r)
+	if r.Error != nil {
+		code, msg := errorCodeMessage(r.Error)
+		if isServiceError(r) {
+			m.AWSException, m.AWSExceptionMessage = code, msg
+		} else {
+			m.SDKException, m.SDKExceptionMessage = code, msg
+		}
+
+		if willRetry(r) {
+			m.RetryReason = code
+		}
+	}
+
+	rep.publish(r, m)
+}
+
+func (rep *Reporter) apiCallHandler(r *request.Request) {
+	m := rep.newMetric(r, APICallType)
+	m.Latency = milliseconds(time.Since(r.Time))
+
+	attempts := r.RetryCount + 1
+	m.AttemptCount = &attempts
+
+	m.FinalHTTPStatusCode = statusCode(r)
+	if r.Error != nil {
+		code, msg := errorCodeMessage(r.Error)
+		if isServiceError(r) {
+			m.FinalAWSException, m.FinalAWSExceptionMessage = code, msg
+		} else {
+			m.FinalSDKException, m.FinalSDKExceptionMessage = code, msg
+		}
+
+		// A retryable error which was not retried exceeded the retries.
+		exceeded := 0
+		if service.BoolValue(r.Retryable) && r.Retryer != nil && r.RetryCount >= r.MaxRetries() {
+			exceeded = 1
+		}
+		m.MaxRetriesExceeded = &exceeded
+	}
+
+	rep.publish(r, m)
+}
+
+// publish publishes the metric, logging errors publishing it if debug
+// logging is enabled.
+func (rep *Reporter) publish(r *request.Request, m *Metric) {
+	err := rep.publisher.Publish(m)
+	if err != nil && r.Config.LogLevel.AtLeast(service.LogDebug) {
+		r.Config.LogFields(service.SeverityDebug, "Failed to publish metric",
+			r.LogContext("type", m.Type, "error", err)...)
+	}
+}
+
+// statusCode returns the status code of the request's response, or nil if
+// no response was received.
+func statusCode(r *request.Request) *int {
+	if r.HTTPResponse == nil || r.HTTPResponse.StatusCode == 0 {
+		return nil
+	}
+
+	code := r.HTTPResponse.StatusCode
+	return &code
+}
+
+// isServiceError returns whether the request's error was returned by the
+// service, instead of failing in the client.
+func isServiceError(r *request.Request) bool {
+	if _, ok := r.Error.(awserr.RequestFailure); ok {
+		return true
+	}
+
+	return r.HTTPResponse != nil && r.HTTPResponse.StatusCode >= 300
+}
+
+// willRetry returns whether the request's failed attempt will be retried.
+// The attempt completes before the Retry handlers resolve whether the
+// request is retryable, so the request's retry state is not modified.
+func willRetry(r *request.Request) bool {
+	if r.Error == nil || r.Retryer == nil || r.RetryCount >= r.MaxRetries() {
+		return false
+	}
+	if r.Retryable != nil {
+		return *r.Retryable
+	}
+	if r.IsErrorClockSkew() {
+		return !r.ClockSkewRetried
+	}
+	if aerr, ok := r.Error.(awserr.Error); ok && aerr.Code() == request.CanceledErrCode {
+		return false
+	}
+	if r.HTTPResponse == nil {
+		return false
+	}
+
+	return r.ShouldRetry(r)
+}
+
+// errorCodeMessage returns the error code and message of the error.
+func errorCodeMessage(err error) (string, string) {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code(), aerr.Message()
+	}
+
+	return "Error", err.Error()
+}
+
+func milliseconds(d time.Duration) *int {
+	ms := int(d / time.Millisecond)
+	return &ms
+}
@@ -0,0 +1,241 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awstesting/mock"
+	"github.com/golib/aws/service/credentials"
+	"github.com/golib/aws/service/request"
+)
+
+type memoryPublisher struct {
+	mu      sync.Mutex
+	metrics []*Metric
+}
+
+func (p *memoryPublisher) Publish(m *Metric) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.metrics = append(p.metrics, m)
+	return nil
+}
+
+func newMockRequest(server *mock.Server, publisher Publisher, cfgs ...*service.Config) *request.Request {
+	svc := server.NewClient(cfgs...)
+	New("client-id", publisher).AddHandlers(&svc.Handlers)
+
+	req := svc.NewRequest(&request.Operation{Name: "ListTables", HTTPMethod: "POST", HTTPPath: "/"}, nil, nil)
+	req.HTTPRequest.Header.Set("X-Aws-Target", "Mock.ListTables")
+	req.Handlers.Retry.PushBack(func(r *request.Request) {
+		r.RetryDelay = 0
+	})
+
+	return req
+}
+
+type countingProvider struct {
+	mu        sync.Mutex
+	retrieves int
+}
+
+func (p *countingProvider) Retrieve() (credentials.Value, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.retrieves++
+	return credentials.Value{AccessKeyID: "AKID", SecretAccessKey: "SECRET", ProviderName: "CountingProvider"}, nil
+}
+
+func (p *countingProvider) IsExpired() bool {
+	return false
+}
+
+func TestReporterDoesNotRefreshCredentials(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(mock.JSONResponse(200, `{}`))
+
+	provider := &countingProvider{}
+	creds := credentials.NewCredentials(provider)
+
+	publisher := &memoryPublisher{}
+	req := newMockRequest(server, publisher, service.NewConfig().WithCredentials(creds))
+
+	// The credentials expire after the request is signed.
+	req.Handlers.Send.PushBack(func(r *request.Request) {
+		creds.Expire()
+	})
+
+	assert.Nil(t, req.Send())
+	assert.Equal(t, 1, provider.retrieves)
+	if assert.Len(t, publisher.metrics, 2) {
+		assert.Equal(t, "CountingProvider", publisher.metrics[0].CredentialProvider)
+	}
+}
+
+func TestReporterRetriedCall(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(
+		mock.ServerErrorResponse(),
+		mock.JSONResponse(200, `{}`).WithHeader("X-Aws-Request-Id", "abc"),
+	)
+
+	publisher := &memoryPublisher{}
+	req := newMockRequest(server, publisher, service.NewConfig().WithMaxRetries(2))
+
+	err := req.Send()
+	assert.Nil(t, err)
+
+	if !assert.Len(t, publisher.metrics, 3) {
+		return
+	}
+
+	failed, succeeded, call := publisher.metrics[0], publisher.metrics[1], publisher.metrics[2]
+	for _, m := range publisher.metrics {
+		assert.Equal(t, "client-id", m.ClientID)
+		assert.Equal(t, Version, m.Version)
+		assert.Equal(t, "Mock", m.Service)
+		assert.Equal(t, "ListTables", m.API)
+		assert.Equal(t, "mock-region", m.Region)
+		assert.NotZero(t, m.Timestamp)
+	}
+
+	assert.Equal(t, APICallAttemptType, failed.Type)
+	assert.Equal(t, 500, *failed.HTTPStatusCode)
+	assert.Equal(t, "UnknownError", failed.AWSException)
+	assert.Empty(t, failed.SDKException)
+	assert.Equal(t, "127.0.0.1", failed.Fqdn)
+	assert.Equal(t, credentials.StaticProviderName, failed.CredentialProvider)
+	assert.NotNil(t, failed.AttemptLatency)
+	assert.Equal(t, "UnknownError", failed.RetryReason)
+
+	assert.Equal(t, APICallAttemptType, succeeded.Type)
+	assert.Equal(t, 200, *succeeded.HTTPStatusCode)
+	assert.Empty(t, succeeded.AWSException)
+	assert.Empty(t, succeeded.RetryReason)
+
+	assert.Equal(t, APICallType, call.Type)
+	assert.Equal(t, 2, *call.AttemptCount)
+	assert.Equal(t, 200, *call.FinalHTTPStatusCode)
+	assert.NotNil(t, call.Latency)
+	assert.Nil(t, call.MaxRetriesExceeded)
+	assert.Nil(t, call.AttemptLatency)
+}
+
+func TestReporterMaxRetriesExceeded(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(mock.ServerErrorResponse())
+
+	publisher := &memoryPublisher{}
+	req := newMockRequest(server, publisher, service.NewConfig().WithMaxRetries(1))
+
+	err := req.Send()
+	assert.NotNil(t, err)
+
+	if !assert.Len(t, publisher.metrics, 3) {
+		return
+	}
+
+	// The last attempt is not retried.
+	assert.Equal(t, "UnknownError", publisher.metrics[0].RetryReason)
+	assert.Empty(t, publisher.metrics[1].RetryReason)
+
+	call := publisher.metrics[2]
+	assert.Equal(t, APICallType, call.Type)
+	assert.Equal(t, 2, *call.AttemptCount)
+	assert.Equal(t, 500, *call.FinalHTTPStatusCode)
+	assert.Equal(t, "UnknownError", call.FinalAWSException)
+	assert.Equal(t, 1, *call.MaxRetriesExceeded)
+}
+
+func TestReporterSDKException(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(mock.ConnectionResetResponse())
+
+	publisher := &memoryPublisher{}
+	req := newMockRequest(server, publisher, service.NewConfig().WithMaxRetries(0))
+
+	err := req.Send()
+	assert.NotNil(t, err)
+
+	if !assert.Len(t, publisher.metrics, 2) {
+		return
+	}
+
+	attempt, call := publisher.metrics[0], publisher.metrics[1]
+	assert.Nil(t, attempt.HTTPStatusCode)
+	assert.NotEmpty(t, attempt.SDKException)
+	assert.Empty(t, attempt.AWSException)
+	assert.Empty(t, attempt.RetryReason)
+	assert.Equal(t, attempt.SDKException, call.FinalSDKException)
+	assert.Equal(t, 1, *call.AttemptCount)
+}
+
+func TestReporterPublishError(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(mock.JSONResponse(200, `{}`))
+
+	publisher := PublisherFunc(func(m *Metric) error {
+		return errors.New("publish failed")
+	})
+	req := newMockRequest(server, publisher)
+
+	assert.Nil(t, req.Send())
+}
+
+func TestUDPPublisher(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	publisher, err := NewUDPPublisher(conn.LocalAddr().String())
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer publisher.Close()
+
+	attempts := 3
+	err = publisher.Publish(&Metric{
+		ClientID:     "client-id",
+		Type:         APICallType,
+		Version:      Version,
+		Service:      "Mock",
+		API:          "ListTables",
+		AttemptCount: &attempts,
+	})
+	assert.Nil(t, err)
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	var m map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf[:n], &m))
+	assert.Equal(t, "client-id", m["ClientId"])
+	assert.Equal(t, "ApiCall", m["Type"])
+	assert.Equal(t, "ListTables", m["Api"])
+	assert.Equal(t, float64(3), m["AttemptCount"])
+	assert.NotContains(t, m, "HttpStatusCode")
+}
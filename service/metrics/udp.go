@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+)
+
+// DefaultUDPAddr is the default address of the client-side monitoring agent
+// metrics are published to.
+const DefaultUDPAddr = "127.0.0.1:31000"
+
+// A UDPPublisher publishes metrics as JSON, one per datagram, to a UDP
+// address. Sending is connectionless, so metrics are dropped without an
+// error if no agent is listening. A UDPPublisher is safe to use
+// concurrently.
+type UDPPublisher struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewUDPPublisher returns a new UDPPublisher publishing metrics to the
+// address, e.g. DefaultUDPAddr. An error is returned if the address cannot
+// be resolved.
+func NewUDPPublisher(addr string) (*UDPPublisher, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UDPPublisher{conn: conn}, nil
+}
+
+// Publish sends the metric.
+func (p *UDPPublisher) Publish(m *Metric) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err = p.conn.Write(b)
+	return err
+}
+
+// Close closes the publisher's connection.
+func (p *UDPPublisher) Close() error {
+	return p.conn.Close()
+}
@@ -0,0 +1,39 @@
+package request
+
+import (
+	"sync"
+	"time"
+)
+
+// clockSkews tracks the offset between the local clock and the clock of each
+// service endpoint a response has been received from.
+var clockSkews = struct {
+	sync.RWMutex
+	m map[string]time.Duration
+}{
+	m: map[string]time.Duration{},
+}
+
+// SetClockSkew stores the offset between the service endpoint's clock and
+// the local clock. A positive skew means the service's clock is ahead of
+// the local clock.
+func SetClockSkew(endpoint string, skew time.Duration) {
+	clockSkews.Lock()
+	defer clockSkews.Unlock()
+
+	if skew == 0 {
+		delete(clockSkews.m, endpoint)
+		return
+	}
+
+	clockSkews.m[endpoint] = skew
+}
+
+// ClockSkew returns the offset between the service endpoint's clock and the
+// local clock. Zero is returned if no offset is known for the endpoint.
+func ClockSkew(endpoint string) time.Duration {
+	clockSkews.RLock()
+	defer clockSkews.RUnlock()
+
+	return clockSkews.m[endpoint]
+}
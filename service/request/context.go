@@ -0,0 +1,115 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+)
+
+const (
+	// CanceledErrCode is the error code that will be returned by an
+	// API request that was canceled. Requests given a context may
+	// return this error when canceled.
+	CanceledErrCode = "RequestCanceled"
+)
+
+// errRequestCanceled is the underlying error of a request canceled through
+// its http.Request's Cancel channel.
+var errRequestCanceled = errors.New("net/http: request canceled")
+
+// SetContext adds a Context to the current request that can be used to cancel
+// an in-flight request. The Context value must not be nil, or this method
+// will panic.
+//
+// The context is also set on the request's underlying http.Request, so
+// canceling the context will abort the request while it is being sent,
+// as well as any retry delay the request is waiting on.
+func (r *Request) SetContext(ctx context.Context) {
+	if ctx == nil {
+		panic("context cannot be nil")
+	}
+
+	r.context = ctx
+	r.HTTPRequest = r.HTTPRequest.WithContext(ctx)
+}
+
+// Context returns the Context set on the request. If no Context has been
+// set, context.Background is returned.
+func (r *Request) Context() context.Context {
+	if r.context != nil {
+		return r.context
+	}
+
+	return context.Background()
+}
+
+// IsErrorCanceled returns true if the request was canceled, either by its
+// context or by the http.Request's Cancel channel.
+func (r *Request) IsErrorCanceled() bool {
+	if err, ok := r.Error.(awserr.Error); ok {
+		return err.Code() == CanceledErrCode
+	}
+
+	return false
+}
+
+// canceledError returns the cancellation error for the request if its
+// context is done or its http.Request's Cancel channel has been closed.
+// Nil is returned if the request has not been canceled.
+func (r *Request) canceledError() error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+
+	if r.HTTPRequest != nil && r.HTTPRequest.Cancel != nil {
+		select {
+		case <-r.HTTPRequest.Cancel:
+			return errRequestCanceled
+		default:
+		}
+	}
+
+	return nil
+}
+
+// setErrorIfCanceled replaces the request's error with a CanceledErrCode
+// error if the request has been canceled. Returns true if the request was
+// canceled.
+func (r *Request) setErrorIfCanceled(origErr error) bool {
+	err := r.canceledError()
+	if err == nil {
+		return false
+	}
+
+	if origErr == nil {
+		origErr = err
+	}
+
+	r.Error = awserr.New(CanceledErrCode, "request context canceled", origErr)
+	r.Retryable = service.Bool(false)
+
+	return true
+}
+
+// SleepWithContext will wait for the timer duration to expire, or the context
+// is canceled. Whichever happens first. If the context is canceled the
+// Context's error will be returned.
+func SleepWithContext(ctx context.Context, dur time.Duration) error {
+	if dur <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(dur)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return nil
+}
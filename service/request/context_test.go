@@ -0,0 +1,101 @@
+package request_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/request"
+)
+
+func TestRequestSetContext(t *testing.T) {
+	s := awstesting.NewClient()
+	r := s.NewRequest(&request.Operation{Name: "Operation"}, nil, nil)
+	assert.Equal(t, context.Background(), r.Context())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r.SetContext(ctx)
+	assert.Equal(t, ctx, r.Context())
+	assert.Equal(t, ctx, r.HTTPRequest.Context())
+}
+
+func TestRequestSetContextNilPanics(t *testing.T) {
+	s := awstesting.NewClient()
+	r := s.NewRequest(&request.Operation{Name: "Operation"}, nil, nil)
+
+	assert.Panics(t, func() {
+		r.SetContext(nil)
+	})
+}
+
+func TestRequestSendCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	reqNum := 0
+	s := awstesting.NewClient(service.NewConfig().WithMaxRetries(10))
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		reqNum++
+		cancel()
+		r.Error = r.HTTPRequest.Context().Err()
+		r.Retryable = service.Bool(true)
+	})
+
+	r := s.NewRequestWithContext(ctx, &request.Operation{Name: "Operation"}, nil, nil)
+	err := r.Send()
+	assert.Error(t, err)
+	assert.Equal(t, request.CanceledErrCode, err.(awserr.Error).Code())
+	assert.Equal(t, context.Canceled, err.(awserr.Error).OrigErr())
+	assert.True(t, r.IsErrorCanceled())
+	assert.Equal(t, 1, reqNum)
+}
+
+func TestRequestRetryDelayCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	reqNum := 0
+	s := awstesting.NewClient(service.NewConfig().WithMaxRetries(10))
+	s.Retryer = constantRetryer{delay: time.Minute}
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		reqNum++
+		r.HTTPResponse = &http.Response{StatusCode: 500, Body: body(``)}
+	})
+
+	r := s.NewRequestWithContext(ctx, &request.Operation{Name: "Operation"}, nil, nil)
+
+	start := time.Now()
+	err := r.Send()
+	assert.True(t, time.Since(start) < time.Minute, "expect retry delay to be interrupted")
+	assert.Error(t, err)
+	assert.Equal(t, request.CanceledErrCode, err.(awserr.Error).Code())
+	assert.Equal(t, 1, reqNum)
+}
+
+func TestSleepWithContext(t *testing.T) {
+	assert.NoError(t, request.SleepWithContext(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := request.SleepWithContext(ctx, time.Minute)
+	assert.Equal(t, context.Canceled, err)
+}
+
+type constantRetryer struct {
+	delay time.Duration
+}
+
+func (r constantRetryer) RetryRules(*request.Request) time.Duration { return r.delay }
+func (r constantRetryer) ShouldRetry(*request.Request) bool         { return true }
+func (r constantRetryer) MaxRetries() int                           { return 10 }
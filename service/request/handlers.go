@@ -1,8 +1,14 @@
 package request
 
 import (
+	"bytes"
 	"fmt"
+	"runtime/debug"
 	"strings"
+	"time"
+
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
 )
 
 // A Handlers provides a collection of request handlers for various
@@ -18,6 +24,8 @@ type Handlers struct {
 	UnmarshalError   HandlerList
 	Retry            HandlerList
 	AfterRetry       HandlerList
+	CompleteAttempt  HandlerList
+	Complete         HandlerList
 }
 
 // Copy returns of this handler's lists.
@@ -33,6 +41,8 @@ func (h *Handlers) Copy() Handlers {
 		UnmarshalMeta:    h.UnmarshalMeta.copy(),
 		Retry:            h.Retry.copy(),
 		AfterRetry:       h.AfterRetry.copy(),
+		CompleteAttempt:  h.CompleteAttempt.copy(),
+		Complete:         h.Complete.copy(),
 	}
 }
 
@@ -48,6 +58,70 @@ func (h *Handlers) Clear() {
 	h.ValidateResponse.Clear()
 	h.Retry.Clear()
 	h.AfterRetry.Clear()
+	h.CompleteAttempt.Clear()
+	h.Complete.Clear()
+}
+
+// String returns the names of the handlers in each of the handler lists, in
+// the order the lists are run. Useful for debugging the handlers installed
+// on a service client or request.
+func (h Handlers) String() string {
+	lists := []struct {
+		name string
+		list HandlerList
+	}{
+		{"Validate", h.Validate},
+		{"Build", h.Build},
+		{"Sign", h.Sign},
+		{"Send", h.Send},
+		{"UnmarshalMeta", h.UnmarshalMeta},
+		{"ValidateResponse", h.ValidateResponse},
+		{"UnmarshalError", h.UnmarshalError},
+		{"Retry", h.Retry},
+		{"AfterRetry", h.AfterRetry},
+		{"Unmarshal", h.Unmarshal},
+		{"CompleteAttempt", h.CompleteAttempt},
+		{"Complete", h.Complete},
+	}
+
+	var buf bytes.Buffer
+	for _, l := range lists {
+		fmt.Fprintf(&buf, "%s: %s\n", l.name, l.list)
+	}
+	return buf.String()
+}
+
+// listName returns the name of the handler list l in h, or an empty string
+// if l is not one of h's handler lists.
+func (h *Handlers) listName(l *HandlerList) string {
+	switch l {
+	case &h.Validate:
+		return "Validate"
+	case &h.Build:
+		return "Build"
+	case &h.Sign:
+		return "Sign"
+	case &h.Send:
+		return "Send"
+	case &h.ValidateResponse:
+		return "ValidateResponse"
+	case &h.Unmarshal:
+		return "Unmarshal"
+	case &h.UnmarshalMeta:
+		return "UnmarshalMeta"
+	case &h.UnmarshalError:
+		return "UnmarshalError"
+	case &h.Retry:
+		return "Retry"
+	case &h.AfterRetry:
+		return "AfterRetry"
+	case &h.CompleteAttempt:
+		return "CompleteAttempt"
+	case &h.Complete:
+		return "Complete"
+	default:
+		return ""
+	}
 }
 
 // A HandlerListRunItem represents an entry in the HandlerList which
@@ -119,21 +193,135 @@ func (l *HandlerList) PushFrontNamed(n NamedHandler) {
 	l.list = append([]NamedHandler{n}, l.list...)
 }
 
-// Remove removes a NamedHandler n
+// Remove removes all handlers in the list with the same name as NamedHandler n.
 func (l *HandlerList) Remove(n NamedHandler) {
-	newlist := []NamedHandler{}
+	l.RemoveByName(n.Name)
+}
+
+// RemoveByName removes all handlers in the list with the name. The list is
+// rebuilt rather than modified in place, so copies of the list and a Run in
+// progress are not affected.
+func (l *HandlerList) RemoveByName(name string) {
+	newlist := make([]NamedHandler, 0, len(l.list))
 	for _, m := range l.list {
-		if m.Name != n.Name {
+		if m.Name != name {
 			newlist = append(newlist, m)
 		}
 	}
 	l.list = newlist
 }
 
+// InsertBefore inserts named handler n before the first handler in the list
+// with the name. Returns false, and does not insert n, if no handler with
+// the name is in the list.
+func (l *HandlerList) InsertBefore(name string, n NamedHandler) bool {
+	i := l.index(name)
+	if i < 0 {
+		return false
+	}
+
+	l.insert(i, n)
+	return true
+}
+
+// InsertAfter inserts named handler n after the last handler in the list
+// with the name. Returns false, and does not insert n, if no handler with
+// the name is in the list.
+func (l *HandlerList) InsertAfter(name string, n NamedHandler) bool {
+	i := l.lastIndex(name)
+	if i < 0 {
+		return false
+	}
+
+	l.insert(i+1, n)
+	return true
+}
+
+// Swap replaces all handlers in the list with the name with named handler
+// replace. Returns true if any handlers were swapped.
+func (l *HandlerList) Swap(name string, replace NamedHandler) bool {
+	var newlist []NamedHandler
+	for i, m := range l.list {
+		if m.Name != name {
+			continue
+		}
+		if newlist == nil {
+			newlist = append([]NamedHandler{}, l.list...)
+		}
+		newlist[i] = replace
+	}
+
+	if newlist == nil {
+		return false
+	}
+
+	l.list = newlist
+	return true
+}
+
+// SwapNamed replaces all handlers in the list with the same name as named
+// handler n with n. Returns true if any handlers were swapped.
+func (l *HandlerList) SwapNamed(n NamedHandler) bool {
+	return l.Swap(n.Name, n)
+}
+
+// Names returns the names of the handlers in the list, in the order they
+// will be run.
+func (l *HandlerList) Names() []string {
+	names := make([]string, len(l.list))
+	for i, m := range l.list {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// String returns the names of the handlers in the list.
+func (l HandlerList) String() string {
+	return "[" + strings.Join(l.Names(), ", ") + "]"
+}
+
+// index returns the index of the first handler with the name, or -1.
+func (l *HandlerList) index(name string) int {
+	for i, m := range l.list {
+		if m.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastIndex returns the index of the last handler with the name, or -1.
+func (l *HandlerList) lastIndex(name string) int {
+	for i := len(l.list) - 1; i >= 0; i-- {
+		if l.list[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// insert inserts named handler n at index i of the list. The list is
+// rebuilt so copies of the list are not affected.
+func (l *HandlerList) insert(i int, n NamedHandler) {
+	newlist := make([]NamedHandler, 0, len(l.list)+1)
+	newlist = append(newlist, l.list[:i]...)
+	newlist = append(newlist, n)
+	newlist = append(newlist, l.list[i:]...)
+	l.list = newlist
+}
+
 // Run executes all handlers in the list with a given request object.
+//
+// If the request's Config enables RecoverHandlerPanics a panicking handler
+// fails the request with a HandlerPanicError instead of crashing, and the
+// remaining handlers of the list are not run. If the Config enables
+// RecordHandlerTimings the duration of each handler is appended to the
+// request's HandlerTimings.
 func (l *HandlerList) Run(r *Request) {
 	for i, h := range l.list {
-		h.Fn(r)
+		if panicked := l.runHandler(r, h); panicked {
+			return
+		}
 		item := HandlerListRunItem{
 			Index:   i,
 			Handler: h,
@@ -145,6 +333,105 @@ func (l *HandlerList) Run(r *Request) {
 	}
 }
 
+// runHandler runs the handler with the request, recovering panics and
+// recording the handler's duration if enabled by the request's Config.
+// Returns true if a panic of the handler was recovered.
+func (l *HandlerList) runHandler(r *Request, h NamedHandler) (panicked bool) {
+	record := service.BoolValue(r.Config.RecordHandlerTimings)
+	recoverPanics := service.BoolValue(r.Config.RecoverHandlerPanics)
+	if !record && !recoverPanics {
+		h.Fn(r)
+		return false
+	}
+
+	start := time.Now()
+	defer func() {
+		if recoverPanics {
+			if p := recover(); p != nil {
+				r.Error = newHandlerPanicError(h.Name, p, debug.Stack())
+				r.Retryable = service.Bool(false)
+				panicked = true
+			}
+		}
+
+		if record {
+			r.HandlerTimings = append(r.HandlerTimings, HandlerTiming{
+				List:     r.Handlers.listName(l),
+				Name:     h.Name,
+				Start:    start,
+				Duration: time.Since(start),
+			})
+		}
+	}()
+
+	h.Fn(r)
+	return false
+}
+
+// A HandlerTiming is the duration a request handler took to run.
+type HandlerTiming struct {
+	// List is the name of the request's handler list the handler was run
+	// from, e.g. "Build" or "Send". Empty if the handler list is not one of
+	// the request's handler lists.
+	List string
+
+	// Name is the name of the handler.
+	Name string
+
+	Start    time.Time
+	Duration time.Duration
+}
+
+// HandlerPanicErrCode is the error code for a request handler which
+// panicked while being run.
+const HandlerPanicErrCode = "HandlerPanic"
+
+// A HandlerPanicError is the error a request fails with when one of its
+// handlers panicked, and RecoverHandlerPanics is enabled.
+type HandlerPanicError struct {
+	// Handler is the name of the handler which panicked.
+	Handler string
+
+	// Value is the value the handler panicked with.
+	Value interface{}
+
+	// Stack is the stack trace of the goroutine when the panic was
+	// recovered.
+	Stack []byte
+}
+
+func newHandlerPanicError(handler string, value interface{}, stack []byte) *HandlerPanicError {
+	return &HandlerPanicError{
+		Handler: handler,
+		Value:   value,
+		Stack:   stack,
+	}
+}
+
+// Code returns the error code, HandlerPanicErrCode.
+func (e *HandlerPanicError) Code() string {
+	return HandlerPanicErrCode
+}
+
+// Message returns the message of the error, including the handler's name
+// and the value it panicked with.
+func (e *HandlerPanicError) Message() string {
+	return fmt.Sprintf("request handler %s panicked, %v", e.Handler, e.Value)
+}
+
+// OrigErr returns the value the handler panicked with if it is an error,
+// nil otherwise.
+func (e *HandlerPanicError) OrigErr() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Error returns the string representation of the error, including the stack
+// trace of the panic.
+func (e *HandlerPanicError) Error() string {
+	return awserr.SprintError(e.Code(), e.Message(), string(e.Stack), e.OrigErr())
+}
+
 // HandlerListLogItem logs the request handler and the state of the
 // request's Error value. Always returns true to continue iterating
 // request handlers in a HandlerList.
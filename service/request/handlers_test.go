@@ -1,11 +1,14 @@
 package request_test
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golib/assert"
 
 	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
 	"github.com/golib/aws/service/request"
 )
 
@@ -85,3 +88,185 @@ func TestStopHandlers(t *testing.T) {
 
 	assert.Equal(t, 2, called, "Expect only two handlers to be called")
 }
+
+func TestHandlerListInsertBeforeAfter(t *testing.T) {
+	s := ""
+	named := func(name string) request.NamedHandler {
+		return request.NamedHandler{Name: name, Fn: func(r *request.Request) {
+			s += name
+		}}
+	}
+
+	l := request.HandlerList{}
+	l.PushBackNamed(named("a"))
+	l.PushBackNamed(named("c"))
+
+	assert.True(t, l.InsertBefore("c", named("b")))
+	assert.True(t, l.InsertAfter("c", named("d")))
+	assert.False(t, l.InsertBefore("x", named("y")))
+	assert.False(t, l.InsertAfter("x", named("y")))
+
+	l.Run(&request.Request{})
+	assert.Equal(t, "abcd", s)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, l.Names())
+}
+
+func TestHandlerListInsertDoesNotAffectCopy(t *testing.T) {
+	h := request.Handlers{}
+	h.Build.PushBackNamed(request.NamedHandler{Name: "a", Fn: func(r *request.Request) {}})
+	h.Build.PushBackNamed(request.NamedHandler{Name: "c", Fn: func(r *request.Request) {}})
+
+	c := h.Copy()
+	c.Build.InsertAfter("a", request.NamedHandler{Name: "b", Fn: func(r *request.Request) {}})
+
+	assert.Equal(t, []string{"a", "c"}, h.Build.Names())
+	assert.Equal(t, []string{"a", "b", "c"}, c.Build.Names())
+}
+
+func TestHandlerListSwap(t *testing.T) {
+	s := ""
+	l := request.HandlerList{}
+	l.PushBackNamed(request.NamedHandler{Name: "a", Fn: func(r *request.Request) { s += "a" }})
+	l.PushBackNamed(request.NamedHandler{Name: "b", Fn: func(r *request.Request) { s += "b" }})
+	l.PushBackNamed(request.NamedHandler{Name: "a", Fn: func(r *request.Request) { s += "a" }})
+
+	assert.True(t, l.Swap("a", request.NamedHandler{Name: "c", Fn: func(r *request.Request) { s += "c" }}))
+	assert.False(t, l.Swap("a", request.NamedHandler{Name: "d", Fn: func(r *request.Request) { s += "d" }}))
+	assert.True(t, l.SwapNamed(request.NamedHandler{Name: "b", Fn: func(r *request.Request) { s += "B" }}))
+	assert.False(t, l.SwapNamed(request.NamedHandler{Name: "x", Fn: func(r *request.Request) {}}))
+
+	l.Run(&request.Request{})
+	assert.Equal(t, "cBc", s)
+	assert.Equal(t, []string{"c", "b", "c"}, l.Names())
+}
+
+func TestHandlerListRemoveAll(t *testing.T) {
+	l := request.HandlerList{}
+	l.PushBackNamed(request.NamedHandler{Name: "a", Fn: func(r *request.Request) {}})
+	l.PushBackNamed(request.NamedHandler{Name: "b", Fn: func(r *request.Request) {}})
+	l.PushFrontNamed(request.NamedHandler{Name: "a", Fn: func(r *request.Request) {}})
+	l.PushBackNamed(request.NamedHandler{Name: "a", Fn: func(r *request.Request) {}})
+
+	l.Remove(request.NamedHandler{Name: "a"})
+	assert.Equal(t, []string{"b"}, l.Names())
+
+	l.RemoveByName("b")
+	assert.Equal(t, 0, l.Len())
+}
+
+func TestHandlersString(t *testing.T) {
+	h := request.Handlers{}
+	h.Build.PushBackNamed(request.NamedHandler{Name: "core.BuildContentLengthHandler", Fn: func(r *request.Request) {}})
+	h.Build.PushBack(func(r *request.Request) {})
+	h.Send.PushBackNamed(request.NamedHandler{Name: "core.SendHandler", Fn: func(r *request.Request) {}})
+
+	str := h.String()
+	assert.Contains(t, str, "Validate: []\n")
+	assert.Contains(t, str, "Build: [core.BuildContentLengthHandler, __anonymous]\n")
+	assert.Contains(t, str, "Send: [core.SendHandler]\n")
+	assert.Equal(t, "[core.SendHandler]", h.Send.String())
+}
+
+func TestHandlerListRecoverPanic(t *testing.T) {
+	l := request.HandlerList{}
+	l.AfterEachFn = request.HandlerListStopOnError
+	l.PushBackNamed(request.NamedHandler{Name: "panicker", Fn: func(r *request.Request) {
+		panic("boom")
+	}})
+	l.PushBackNamed(request.NamedHandler{Name: "next", Fn: func(r *request.Request) {
+		assert.Fail(t, "handler after panic should not be called")
+	}})
+
+	r := &request.Request{Config: service.Config{RecoverHandlerPanics: service.Bool(true)}}
+	assert.NotPanics(t, func() { l.Run(r) })
+
+	err, ok := r.Error.(*request.HandlerPanicError)
+	assert.True(t, ok, "expect HandlerPanicError, got %T", r.Error)
+	assert.Equal(t, request.HandlerPanicErrCode, err.Code())
+	assert.Equal(t, "panicker", err.Handler)
+	assert.Equal(t, "boom", err.Value)
+	assert.Contains(t, err.Message(), "panicker")
+	assert.Contains(t, err.Error(), "handlers_test.go")
+	assert.Nil(t, err.OrigErr())
+	assert.False(t, *r.Retryable)
+}
+
+func TestHandlerListRecoverPanicError(t *testing.T) {
+	origErr := fmt.Errorf("orig error")
+
+	l := request.HandlerList{}
+	l.PushBackNamed(request.NamedHandler{Name: "panicker", Fn: func(r *request.Request) {
+		panic(origErr)
+	}})
+
+	r := &request.Request{Config: service.Config{RecoverHandlerPanics: service.Bool(true)}}
+	l.Run(r)
+
+	err := r.Error.(awserr.Error)
+	assert.Equal(t, request.HandlerPanicErrCode, err.Code())
+	assert.Equal(t, origErr, err.OrigErr())
+}
+
+func TestHandlerListRecoverPanicStopsList(t *testing.T) {
+	sent := 0
+
+	l := request.HandlerList{}
+	l.PushBackNamed(request.NamedHandler{Name: "panicker", Fn: func(r *request.Request) {
+		panic("boom")
+	}})
+	l.PushBackNamed(request.NamedHandler{Name: "send", Fn: func(r *request.Request) {
+		sent++
+	}})
+
+	// Without AfterEachFn handlers after the panicking handler are not run.
+	r := &request.Request{Config: service.Config{RecoverHandlerPanics: service.Bool(true)}}
+	l.Run(r)
+
+	assert.Equal(t, 0, sent)
+	_, ok := r.Error.(*request.HandlerPanicError)
+	assert.True(t, ok, "expect HandlerPanicError, got %T", r.Error)
+}
+
+func TestHandlerListPanicNotRecovered(t *testing.T) {
+	l := request.HandlerList{}
+	l.PushBack(func(r *request.Request) {
+		panic("boom")
+	})
+
+	assert.Panics(t, func() { l.Run(&request.Request{}) })
+}
+
+func TestHandlerTimings(t *testing.T) {
+	r := &request.Request{Config: service.Config{RecordHandlerTimings: service.Bool(true)}}
+	r.Handlers.Build.PushBackNamed(request.NamedHandler{Name: "slow", Fn: func(r *request.Request) {
+		time.Sleep(10 * time.Millisecond)
+	}})
+	r.Handlers.Build.PushBack(func(r *request.Request) {})
+	r.Handlers.Send.PushBackNamed(request.NamedHandler{Name: "send", Fn: func(r *request.Request) {}})
+
+	r.Handlers.Build.Run(r)
+	r.Handlers.Send.Run(r)
+
+	l := request.HandlerList{}
+	l.PushBackNamed(request.NamedHandler{Name: "other", Fn: func(r *request.Request) {}})
+	l.Run(r)
+
+	assert.Len(t, r.HandlerTimings, 4)
+	assert.Equal(t, "Build", r.HandlerTimings[0].List)
+	assert.Equal(t, "slow", r.HandlerTimings[0].Name)
+	assert.True(t, r.HandlerTimings[0].Duration >= 10*time.Millisecond)
+	assert.Equal(t, "Build", r.HandlerTimings[1].List)
+	assert.Equal(t, "__anonymous", r.HandlerTimings[1].Name)
+	assert.Equal(t, "Send", r.HandlerTimings[2].List)
+	assert.Equal(t, "send", r.HandlerTimings[2].Name)
+	assert.Equal(t, "", r.HandlerTimings[3].List)
+	assert.Equal(t, "other", r.HandlerTimings[3].Name)
+}
+
+func TestHandlerTimingsDisabled(t *testing.T) {
+	r := &request.Request{}
+	r.Handlers.Build.PushBack(func(r *request.Request) {})
+	r.Handlers.Build.Run(r)
+
+	assert.Len(t, r.HandlerTimings, 0)
+}
@@ -0,0 +1,248 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// hedgingSampleSize is the number of recent latencies a HedgingPolicy
+	// computes its hedging delay from.
+	hedgingSampleSize = 100
+
+	// hedgingMinSamples is the number of latencies a HedgingPolicy must
+	// have recorded before the percentile is used as the hedging delay.
+	hedgingMinSamples = 10
+)
+
+// A HedgingPolicy controls hedging of requests. A hedged request sends a
+// second attempt if the first has not responded within the hedging delay.
+// Whichever attempt completes first successfully is used, and the other is
+// canceled.
+//
+// The hedging delay is the Percentile of the latencies of recent requests
+// sent with the policy, so a policy should be shared by all requests of an
+// API operation. Only requests for operations marked Idempotent are hedged.
+//
+//	hedging := request.NewHedgingPolicy(0.95, 100*time.Millisecond)
+//	req := svc.NewRequest(op, params, out, request.WithHedging(hedging))
+type HedgingPolicy struct {
+	// Percentile of recent request latencies to use as the hedging delay,
+	// between 0 and 1.
+	Percentile float64
+
+	// DefaultDelay is the hedging delay used until enough latencies have
+	// been recorded to compute the percentile.
+	DefaultDelay time.Duration
+
+	// MinDelay is the minimum hedging delay.
+	MinDelay time.Duration
+
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// NewHedgingPolicy returns a new HedgingPolicy hedging requests which take
+// longer than the percentile of recent request latencies.
+func NewHedgingPolicy(percentile float64, defaultDelay time.Duration) *HedgingPolicy {
+	return &HedgingPolicy{
+		Percentile:   percentile,
+		DefaultDelay: defaultDelay,
+	}
+}
+
+// WithHedging is a request option that will hedge the request with the
+// policy if the request's operation is Idempotent.
+func WithHedging(policy *HedgingPolicy) Option {
+	return func(r *Request) {
+		r.Hedging = policy
+	}
+}
+
+// Delay returns the duration a hedged request waits for its first attempt
+// before sending the second.
+func (p *HedgingPolicy) Delay() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delay := p.DefaultDelay
+	if len(p.samples) >= hedgingMinSamples {
+		sorted := append([]time.Duration{}, p.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		i := int(p.Percentile * float64(len(sorted)))
+		if i >= len(sorted) {
+			i = len(sorted) - 1
+		} else if i < 0 {
+			i = 0
+		}
+		delay = sorted[i]
+	}
+
+	if delay < p.MinDelay {
+		delay = p.MinDelay
+	}
+
+	return delay
+}
+
+// record adds the latency of a request to the policy's samples.
+func (p *HedgingPolicy) record(latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.samples) < hedgingSampleSize {
+		p.samples = append(p.samples, latency)
+		return
+	}
+
+	p.samples[p.next] = latency
+	p.next = (p.next + 1) % hedgingSampleSize
+}
+
+// hedgeAttempt is the outcome of one attempt of a hedged request.
+type hedgeAttempt struct {
+	req     *Request
+	latency time.Duration
+	index   int
+}
+
+// sendHedged runs the request's Send handlers, hedging the send with a second
+// attempt if the first has not completed within the policy's delay. The
+// response and error of the winning attempt are set on the request.
+func (r *Request) sendHedged() {
+	hedgeBody, ok := r.hedgeBody()
+	if !ok {
+		// The body cannot be read concurrently, send without hedging.
+		r.Handlers.Send.Run(r)
+		return
+	}
+
+	results := make(chan hedgeAttempt, 2)
+	var cancels []context.CancelFunc
+	send := func(httpReq *http.Request) {
+		attempt, cancel := r.newHedgeAttempt(httpReq)
+		cancels = append(cancels, cancel)
+
+		go func(index int) {
+			start := time.Now()
+			attempt.Handlers.Send.Run(attempt)
+			results <- hedgeAttempt{req: attempt, latency: time.Since(start), index: index}
+		}(len(cancels) - 1)
+	}
+
+	send(r.HTTPRequest)
+
+	timer := time.NewTimer(r.Hedging.Delay())
+	defer timer.Stop()
+
+	// pending is the number of attempts whose results have not been received.
+	pending := 1
+	var winner hedgeAttempt
+	select {
+	case winner = <-results:
+		pending--
+	case <-timer.C:
+		send(copyHTTPRequest(r.HTTPRequest, hedgeBody))
+		pending++
+
+		winner = <-results
+		pending--
+		if winner.req.Error != nil {
+			// Prefer the other attempt if the first one to complete failed.
+			cancels[winner.index]()
+			closeHedgeResponse(winner.req)
+
+			winner = <-results
+			pending--
+		}
+	}
+
+	r.Hedging.record(winner.latency)
+
+	// Cancel the losing attempt, and clean up its response once complete.
+	for i, cancel := range cancels {
+		if i != winner.index {
+			cancel()
+		}
+	}
+	if pending > 0 {
+		go func() {
+			loser := <-results
+			closeHedgeResponse(loser.req)
+		}()
+	}
+
+	r.HTTPResponse = winner.req.HTTPResponse
+	r.Error = winner.req.Error
+	r.Retryable = winner.req.Retryable
+	r.HandlerTimings = append(r.HandlerTimings, winner.req.HandlerTimings...)
+
+	// The winner's context is canceled once its response body is closed.
+	if r.HTTPResponse != nil && r.HTTPResponse.Body != nil {
+		r.HTTPResponse.Body = &cancelReadCloser{ReadCloser: r.HTTPResponse.Body, cancel: cancels[winner.index]}
+	} else {
+		cancels[winner.index]()
+	}
+}
+
+// newHedgeAttempt returns a copy of the request for one attempt of a hedged
+// send, using the HTTP request with a cancelable context.
+func (r *Request) newHedgeAttempt(httpReq *http.Request) (*Request, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(r.Context())
+
+	attempt := *r
+	attempt.context = ctx
+	attempt.HTTPRequest = httpReq.WithContext(ctx)
+	attempt.HTTPResponse = nil
+	attempt.HandlerTimings = nil
+
+	return &attempt, cancel
+}
+
+// closeHedgeResponse closes the response body of a discarded attempt.
+func closeHedgeResponse(attempt *Request) {
+	if resp := attempt.HTTPResponse; resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+}
+
+// hedgeBody returns a reader of the request's body which can be read
+// concurrently with the request's own body. False is returned if the body
+// cannot be read concurrently.
+func (r *Request) hedgeBody() (io.ReadCloser, bool) {
+	if r.streamingBody != nil {
+		return nil, false
+	}
+
+	length := r.HTTPRequest.ContentLength
+	if length <= 0 {
+		return newOffsetReader(bytes.NewReader(nil), 0), true
+	}
+
+	ra, ok := r.Body.(io.ReaderAt)
+	if !ok {
+		return nil, false
+	}
+
+	return newOffsetReader(io.NewSectionReader(ra, r.BodyStart, length), 0), true
+}
+
+// cancelReadCloser cancels a context when the reader is closed.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the reader and cancels the context.
+func (c *cancelReadCloser) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,197 @@
+package request_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/client"
+	"github.com/golib/aws/service/request"
+)
+
+// newHedgingClient returns a client where the first send attempt blocks until
+// canceled or the slow duration elapses, and later attempts respond at once.
+func newHedgingClient(slow time.Duration) (*client.Client, *int32, *int32) {
+	var attempts, canceled int32
+
+	s := awstesting.NewClient()
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+		if n == 1 {
+			select {
+			case <-r.HTTPRequest.Context().Done():
+				atomic.AddInt32(&canceled, 1)
+				r.Error = awserr.New("RequestError", "send request failed", r.HTTPRequest.Context().Err())
+				return
+			case <-time.After(slow):
+			}
+		}
+
+		r.HTTPResponse = &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"X-Attempt": []string{string('0' + rune(n))}},
+			Body:       body(``),
+		}
+	})
+
+	return s, &attempts, &canceled
+}
+
+func TestHedgedRequestUsesFasterAttempt(t *testing.T) {
+	s, attempts, canceled := newHedgingClient(time.Minute)
+
+	op := &request.Operation{Name: "GetItem", HTTPMethod: "GET", Idempotent: true}
+	r := s.NewRequest(op, nil, nil, request.WithHedging(request.NewHedgingPolicy(0.95, 10*time.Millisecond)))
+
+	start := time.Now()
+	assert.NoError(t, r.Send())
+	assert.True(t, time.Since(start) < time.Minute)
+	assert.Equal(t, "2", r.HTTPResponse.Header.Get("X-Attempt"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(attempts))
+
+	// The slow attempt is canceled in the background.
+	for i := 0; i < 100 && atomic.LoadInt32(canceled) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(canceled))
+}
+
+func TestHedgedRequestNotHedgedWhenFast(t *testing.T) {
+	s, attempts, _ := newHedgingClient(0)
+
+	op := &request.Operation{Name: "GetItem", HTTPMethod: "GET", Idempotent: true}
+	r := s.NewRequest(op, nil, nil, request.WithHedging(request.NewHedgingPolicy(0.95, time.Minute)))
+
+	assert.NoError(t, r.Send())
+	assert.Equal(t, "1", r.HTTPResponse.Header.Get("X-Attempt"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(attempts))
+}
+
+func TestHedgedRequestRequiresIdempotent(t *testing.T) {
+	s, attempts, _ := newHedgingClient(50 * time.Millisecond)
+
+	op := &request.Operation{Name: "PutItem", HTTPMethod: "PUT"}
+	r := s.NewRequest(op, nil, nil, request.WithHedging(request.NewHedgingPolicy(0.95, time.Millisecond)))
+
+	assert.NoError(t, r.Send())
+	assert.Equal(t, int32(1), atomic.LoadInt32(attempts))
+}
+
+func TestHedgedRequestReplaysBody(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	var attempts int32
+
+	s := awstesting.NewClient()
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+		b, _ := ioutil.ReadAll(r.HTTPRequest.Body)
+
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+
+		if n == 1 {
+			<-r.HTTPRequest.Context().Done()
+			r.Error = awserr.New("RequestError", "send request failed", nil)
+			return
+		}
+		r.HTTPResponse = &http.Response{StatusCode: 200, Header: http.Header{}, Body: body(``)}
+	})
+
+	op := &request.Operation{Name: "Query", HTTPMethod: "POST", Idempotent: true}
+	r := s.NewRequest(op, nil, nil, request.WithHedging(request.NewHedgingPolicy(0.95, 10*time.Millisecond)))
+	r.SetStringBody("request body")
+
+	assert.NoError(t, r.Send())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"request body", "request body"}, bodies)
+}
+
+type closeCounter struct {
+	io.ReadCloser
+	closed *int32
+}
+
+func (c *closeCounter) Close() error {
+	atomic.AddInt32(c.closed, 1)
+	return c.ReadCloser.Close()
+}
+
+func TestHedgedRequestFailedFirstAttemptCleanedUp(t *testing.T) {
+	var attempts, closed int32
+
+	s := awstesting.NewClient()
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		if atomic.AddInt32(&attempts, 1)%2 == 1 {
+			// The first attempt fails after the hedge is sent.
+			time.Sleep(20 * time.Millisecond)
+			r.HTTPResponse = &http.Response{StatusCode: 0, Header: http.Header{}, Body: &closeCounter{ReadCloser: body(``), closed: &closed}}
+			r.Error = awserr.New("RequestError", "send request failed", nil)
+			return
+		}
+
+		time.Sleep(40 * time.Millisecond)
+		r.HTTPResponse = &http.Response{StatusCode: 200, Header: http.Header{}, Body: body(``)}
+	})
+
+	op := &request.Operation{Name: "GetItem", HTTPMethod: "GET", Idempotent: true}
+	policy := request.NewHedgingPolicy(0.95, 5*time.Millisecond)
+	policy.MinDelay = 5 * time.Millisecond
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		r := s.NewRequest(op, nil, nil, request.WithHedging(policy))
+		assert.NoError(t, r.Send())
+		r.HTTPResponse.Body.Close()
+	}
+
+	after := runtime.NumGoroutine()
+	for i := 0; i < 100 && after > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+		after = runtime.NumGoroutine()
+	}
+	assert.True(t, after <= before, "goroutines leaked, %d before, %d after", before, after)
+	assert.Equal(t, int32(20), atomic.LoadInt32(&closed))
+}
+
+func TestHedgingPolicyDelay(t *testing.T) {
+	p := request.NewHedgingPolicy(0.9, time.Second)
+	p.MinDelay = 5 * time.Millisecond
+	assert.Equal(t, time.Second, p.Delay())
+
+	s, _, _ := newHedgingClient(0)
+	op := &request.Operation{Name: "GetItem", HTTPMethod: "GET", Idempotent: true}
+	for i := 0; i < 20; i++ {
+		r := s.NewRequest(op, nil, nil, request.WithHedging(p))
+		assert.NoError(t, r.Send())
+	}
+
+	// Fast responses are bounded by the minimum delay.
+	assert.Equal(t, 5*time.Millisecond, p.Delay())
+}
+
+func TestWithHedgingOption(t *testing.T) {
+	p := request.NewHedgingPolicy(0.5, time.Second)
+	s := awstesting.NewClient(service.NewConfig())
+
+	r := s.NewRequest(&request.Operation{Name: "GetItem"}, nil, nil, request.WithHedging(p))
+	assert.Equal(t, p, r.Hedging)
+}
@@ -0,0 +1,119 @@
+package request
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/golib/aws/service"
+)
+
+// Option is a functional option that can augment or modify a request when
+// using a client's NewRequest method. Options are applied after the request
+// has been created, and before any of its handlers are run.
+type Option func(*Request)
+
+// ApplyOptions will apply each option to the request calling them in the
+// order they were provided.
+func (r *Request) ApplyOptions(opts ...Option) {
+	for _, opt := range opts {
+		opt(r)
+	}
+}
+
+// WithLogLevel is a request option that will set the request to use a
+// specific log level when the request is made.
+//
+//	svc.NewRequest(op, params, out, request.WithLogLevel(service.LogDebugWithHTTPBody))
+func WithLogLevel(l service.LogLevelType) Option {
+	return func(r *Request) {
+		r.Config.LogLevel = service.LogLevel(l)
+	}
+}
+
+// WithRequestRetryer is a request option that will set the Retryer the
+// request uses to decide if, and how long to wait before, it is retried.
+// The retryer will replace the client's Retryer for this request only.
+func WithRequestRetryer(retryer Retryer) Option {
+	return func(r *Request) {
+		r.Retryer = retryer
+	}
+}
+
+// WithHeader is a request option that will add the header key and value to
+// the request's HTTP headers. The header is added after the request has been
+// built, so it will be included in the request's signature.
+func WithHeader(key, value string) Option {
+	return func(r *Request) {
+		r.Handlers.Build.PushBack(func(r *Request) {
+			r.HTTPRequest.Header.Add(key, value)
+		})
+	}
+}
+
+// WithQuery is a request option that will add the key and value to the
+// request's URL query string. The value is added after the request has been
+// built, so it will be included in the request's signature.
+func WithQuery(key, value string) Option {
+	return func(r *Request) {
+		r.Handlers.Build.PushBack(func(r *Request) {
+			query := r.HTTPRequest.URL.Query()
+			query.Add(key, value)
+			r.HTTPRequest.URL.RawQuery = query.Encode()
+		})
+	}
+}
+
+// WithGetResponseHeader is a request option that will populate the value
+// with the named header of the request's HTTP response once it has been
+// received.
+//
+//	var requestID string
+//	svc.NewRequest(op, params, out, request.WithGetResponseHeader("X-Aws-Request-Id", &requestID))
+func WithGetResponseHeader(name string, value *string) Option {
+	return func(r *Request) {
+		r.Handlers.Send.PushBack(func(r *Request) {
+			if r.HTTPResponse != nil {
+				*value = r.HTTPResponse.Header.Get(name)
+			}
+		})
+	}
+}
+
+// WithGetResponseHeaders is a request option that will populate the headers
+// with the request's HTTP response headers once they have been received.
+func WithGetResponseHeaders(headers *http.Header) Option {
+	return func(r *Request) {
+		r.Handlers.Send.PushBack(func(r *Request) {
+			if r.HTTPResponse != nil {
+				*headers = r.HTTPResponse.Header
+			}
+		})
+	}
+}
+
+// WithResponseReadTimeout is a request option that will wrap the response
+// body with a reader which will fail reads that take longer than the
+// duration. A failed read sets a ResponseTimeoutErrCode error on the
+// request, which may be retried.
+func WithResponseReadTimeout(duration time.Duration) Option {
+	return func(r *Request) {
+		r.Handlers.Send.PushBackNamed(NamedHandler{
+			Name: "core.WithResponseReadTimeout",
+			Fn: func(r *Request) {
+				if r.HTTPResponse == nil || r.HTTPResponse.Body == nil {
+					return
+				}
+
+				r.HTTPResponse.Body = &timeoutReadCloser{
+					reader:   r.HTTPResponse.Body,
+					duration: duration,
+				}
+			},
+		})
+
+		r.Handlers.Retry.PushFrontNamed(NamedHandler{
+			Name: "core.AdaptToResponseTimeoutError",
+			Fn:   adaptToResponseTimeoutError,
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package request_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/request"
+)
+
+func TestRequestApplyOptions(t *testing.T) {
+	s := awstesting.NewClient()
+	r := s.NewRequest(&request.Operation{Name: "Operation"}, nil, nil,
+		request.WithLogLevel(service.LogDebugWithHTTPBody),
+		request.WithRequestRetryer(constantRetryer{delay: time.Second}),
+	)
+
+	assert.Equal(t, service.LogDebugWithHTTPBody, r.Config.LogLevel.Value())
+	assert.Equal(t, service.LogOff, s.Config.LogLevel.Value())
+	assert.Equal(t, constantRetryer{delay: time.Second}, r.Retryer)
+}
+
+func TestRequestWithHeaderAndQuery(t *testing.T) {
+	s := awstesting.NewClient()
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+
+	var sent *http.Request
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		sent = r.HTTPRequest
+		r.HTTPResponse = &http.Response{StatusCode: 200, Body: body(``)}
+	})
+
+	r := s.NewRequest(&request.Operation{Name: "Operation", HTTPPath: "/path?a=b"}, nil, nil,
+		request.WithHeader("X-Custom", "value"),
+		request.WithQuery("key", "value"),
+	)
+	assert.NoError(t, r.Send())
+
+	assert.Equal(t, "value", sent.Header.Get("X-Custom"))
+	assert.Equal(t, "b", sent.URL.Query().Get("a"))
+	assert.Equal(t, "value", sent.URL.Query().Get("key"))
+}
+
+func TestRequestWithGetResponseHeader(t *testing.T) {
+	s := awstesting.NewClient()
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"X-Aws-Request-Id": []string{"abc123"}},
+			Body:       body(``),
+		}
+	})
+
+	var requestID string
+	var headers http.Header
+	r := s.NewRequest(&request.Operation{Name: "Operation"}, nil, nil,
+		request.WithGetResponseHeader("X-Aws-Request-Id", &requestID),
+		request.WithGetResponseHeaders(&headers),
+	)
+	assert.NoError(t, r.Send())
+
+	assert.Equal(t, "abc123", requestID)
+	assert.Equal(t, "abc123", headers.Get("X-Aws-Request-Id"))
+}
+
+type slowReader struct {
+	delay time.Duration
+}
+
+func (r slowReader) Read(b []byte) (int, error) {
+	time.Sleep(r.delay)
+	return 0, io.EOF
+}
+
+func TestRequestWithResponseReadTimeout(t *testing.T) {
+	reqNum := 0
+	s := awstesting.NewClient(service.NewConfig().WithMaxRetries(1).WithSleepDelay(func(time.Duration) {}))
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		reqNum++
+		r.HTTPResponse = &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(slowReader{delay: 100 * time.Millisecond}),
+		}
+	})
+	s.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+		if _, err := ioutil.ReadAll(r.HTTPResponse.Body); err != nil {
+			r.Error = awserr.New("SerializationError", "failed to read body", err)
+		}
+	})
+
+	r := s.NewRequest(&request.Operation{Name: "Operation"}, nil, nil,
+		request.WithResponseReadTimeout(10*time.Millisecond),
+	)
+	err := r.Send()
+	assert.Error(t, err)
+	assert.Equal(t, request.ResponseTimeoutErrCode, err.(awserr.Error).Code())
+	assert.Equal(t, 2, reqNum)
+}
@@ -0,0 +1,200 @@
+package request
+
+import (
+	"fmt"
+
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awsutil"
+)
+
+const (
+	// RepeatedPageTokenErrCode is the error code returned by a Pagination
+	// when the service returns the same page tokens for consecutive pages.
+	RepeatedPageTokenErrCode = "RepeatedPageToken"
+)
+
+// A Pagination provides paginating of SDK API operations which are
+// paginatable. Generally you should not use this type directly, but use the
+// "Pages" API operations method to automatically perform pagination for you.
+//
+// Pagination differs from a Paginator type in that pagination is the type
+// that does the pagination between API operations, and Paginator defines
+// the configuration that will be used per page request.
+//
+//	p := request.Pagination{
+//		NewRequest: func() (*request.Request, error) {
+//			return svc.NewRequest(op, input, &ListObjectsOutput{}), nil
+//		},
+//		MaxPages: 10,
+//		Prefetch: true,
+//	}
+//
+//	for p.Next() {
+//		page := p.Page().(*ListObjectsOutput)
+//		// process the page's data
+//	}
+//	if err := p.Err(); err != nil {
+//		return err
+//	}
+type Pagination struct {
+	// Function to return a Request value for each pagination request. Any
+	// configuration or handlers to the request must be applied to the
+	// request prior to being returned from this function. The page tokens
+	// of the previous page are set on the request's Params by Pagination.
+	//
+	// NewRequest must be safe to call from a separate goroutine if Prefetch
+	// is enabled.
+	NewRequest func() (*Request, error)
+
+	// EndPageOnSameToken, when enabled, will allow the paginator to stop on
+	// a repeated page token without an error. Some services return the same
+	// token for the last page. If not enabled a repeated token stops the
+	// pagination with a RepeatedPageTokenErrCode error, preventing an
+	// infinite loop of requests.
+	EndPageOnSameToken bool
+
+	// MaxPages is the maximum number of pages that will be retrieved. Zero
+	// means no limit.
+	MaxPages int
+
+	// Prefetch, when enabled, will request the next page in the background
+	// while the caller is processing the current page.
+	Prefetch bool
+
+	started    bool
+	numPages   int
+	prevTokens []interface{}
+	nextTokens []interface{}
+	prefetched chan pageResult
+
+	err     error
+	curPage interface{}
+}
+
+// pageResult is the outcome of a single page request.
+type pageResult struct {
+	page   interface{}
+	tokens []interface{}
+	err    error
+}
+
+// HasNextPage will return true if Pagination is able to determine that the
+// API operation has additional pages. False will be returned if there are
+// no more pages remaining, the page limit was reached, or an error occurred.
+//
+// Will always return true if Next has not been called yet.
+func (p *Pagination) HasNextPage() bool {
+	if p.err != nil {
+		return false
+	}
+	if !p.started {
+		return true
+	}
+	if p.MaxPages > 0 && p.numPages >= p.MaxPages {
+		return false
+	}
+
+	return len(p.nextTokens) != 0
+}
+
+// Err returns the error Pagination encountered when retrieving the next
+// page.
+func (p *Pagination) Err() error {
+	return p.err
+}
+
+// Page returns the current page. Page should only be called after a
+// successful call to Next. It is undefined what Page will return if Page
+// is called after Next returns false.
+func (p *Pagination) Page() interface{} {
+	return p.curPage
+}
+
+// Next will attempt to retrieve the next page for the API operation. When
+// a page is retrieved true will be returned. If the page cannot be
+// retrieved, or there are no more pages false will be returned.
+//
+// Use the Page method to retrieve the current page data. The data will need
+// to be cast to the API operation's output type.
+//
+// Use the Err method to determine if an error occurred if Next returns
+// false.
+func (p *Pagination) Next() bool {
+	if !p.HasNextPage() {
+		return false
+	}
+
+	var res pageResult
+	if p.prefetched != nil {
+		res = <-p.prefetched
+		p.prefetched = nil
+	} else {
+		res = p.fetch(p.nextTokens)
+	}
+
+	if res.err != nil {
+		p.err = res.err
+		return false
+	}
+
+	p.started = true
+	p.numPages++
+	p.curPage = res.page
+	p.prevTokens, p.nextTokens = p.nextTokens, res.tokens
+
+	if len(p.prevTokens) != 0 && tokensEqual(p.prevTokens, p.nextTokens) {
+		p.nextTokens = nil
+		if !p.EndPageOnSameToken {
+			p.err = awserr.New(RepeatedPageTokenErrCode,
+				fmt.Sprintf("page tokens %v repeated", awsutil.Prettify(p.prevTokens)), nil)
+		}
+	}
+
+	if p.Prefetch && p.HasNextPage() {
+		p.prefetched = make(chan pageResult, 1)
+		go func(tokens []interface{}, c chan<- pageResult) {
+			c <- p.fetch(tokens)
+		}(p.nextTokens, p.prefetched)
+	}
+
+	return true
+}
+
+// fetch creates and sends the request for the page of the tokens. If tokens
+// is empty the first page is requested.
+func (p *Pagination) fetch(tokens []interface{}) pageResult {
+	req, err := p.NewRequest()
+	if err != nil {
+		return pageResult{err: err}
+	}
+
+	if len(tokens) != 0 && req.Operation.Paginator != nil {
+		for i, intok := range req.Operation.InputTokens {
+			awsutil.SetValueAtPath(req.Params, intok, tokens[i])
+		}
+	}
+
+	if err := req.Send(); err != nil {
+		return pageResult{err: err}
+	}
+
+	return pageResult{
+		page:   req.Data,
+		tokens: req.nextPageTokens(),
+	}
+}
+
+// tokensEqual returns if the two sets of page tokens are equal.
+func tokensEqual(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !awsutil.DeepEqual(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,152 @@
+package request_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/client"
+	"github.com/golib/aws/service/request"
+)
+
+type mockListInput struct {
+	NextToken *string
+	MaxItems  *int64
+}
+
+type mockListOutput struct {
+	Items     []*string
+	NextToken *string
+}
+
+var mockListOperation = &request.Operation{
+	Name: "ListItems",
+	Paginator: &request.Paginator{
+		InputTokens:  []string{"NextToken"},
+		OutputTokens: []string{"NextToken"},
+		LimitToken:   "MaxItems",
+	},
+}
+
+// newPaginationClient returns a client responding with the pages, where the
+// page for a token is keyed by the request's NextToken.
+func newPaginationClient(pages map[string]*mockListOutput) (*client.Client, *[]string) {
+	var mu sync.Mutex
+	var tokens []string
+
+	s := awstesting.NewClient()
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{StatusCode: 200, Header: http.Header{}, Body: body(``)}
+	})
+	s.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+		token := service.StringValue(r.Params.(*mockListInput).NextToken)
+
+		mu.Lock()
+		tokens = append(tokens, token)
+		mu.Unlock()
+
+		*r.Data.(*mockListOutput) = *pages[token]
+	})
+
+	return s, &tokens
+}
+
+func newPagination(s *client.Client) *request.Pagination {
+	return &request.Pagination{
+		NewRequest: func() (*request.Request, error) {
+			return s.NewRequest(mockListOperation, &mockListInput{}, &mockListOutput{}), nil
+		},
+	}
+}
+
+func collectPages(p *request.Pagination) []string {
+	var items []string
+	for p.Next() {
+		for _, item := range p.Page().(*mockListOutput).Items {
+			items = append(items, service.StringValue(item))
+		}
+	}
+
+	return items
+}
+
+var mockPages = map[string]*mockListOutput{
+	"":   {Items: service.StringSlice([]string{"a", "b"}), NextToken: service.String("t1")},
+	"t1": {Items: service.StringSlice([]string{"c"}), NextToken: service.String("t2")},
+	"t2": {Items: service.StringSlice([]string{"d"}), NextToken: service.String("")},
+}
+
+func TestPagination(t *testing.T) {
+	s, tokens := newPaginationClient(mockPages)
+	p := newPagination(s)
+
+	assert.True(t, p.HasNextPage())
+	assert.Equal(t, []string{"a", "b", "c", "d"}, collectPages(p))
+	assert.NoError(t, p.Err())
+	assert.False(t, p.HasNextPage())
+	assert.Equal(t, []string{"", "t1", "t2"}, *tokens)
+}
+
+func TestPaginationPrefetch(t *testing.T) {
+	s, tokens := newPaginationClient(mockPages)
+	p := newPagination(s)
+	p.Prefetch = true
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, collectPages(p))
+	assert.NoError(t, p.Err())
+	assert.Equal(t, []string{"", "t1", "t2"}, *tokens)
+}
+
+func TestPaginationMaxPages(t *testing.T) {
+	s, tokens := newPaginationClient(mockPages)
+	p := newPagination(s)
+	p.MaxPages = 2
+	p.Prefetch = true
+
+	assert.Equal(t, []string{"a", "b", "c"}, collectPages(p))
+	assert.NoError(t, p.Err())
+	assert.Equal(t, []string{"", "t1"}, *tokens)
+}
+
+func TestPaginationRepeatedToken(t *testing.T) {
+	pages := map[string]*mockListOutput{
+		"":   {Items: service.StringSlice([]string{"a"}), NextToken: service.String("t1")},
+		"t1": {Items: service.StringSlice([]string{"b"}), NextToken: service.String("t1")},
+	}
+
+	s, _ := newPaginationClient(pages)
+	p := newPagination(s)
+
+	assert.Equal(t, []string{"a", "b"}, collectPages(p))
+	assert.Error(t, p.Err())
+	assert.Equal(t, request.RepeatedPageTokenErrCode, p.Err().(awserr.Error).Code())
+
+	s, tokens := newPaginationClient(pages)
+	p = newPagination(s)
+	p.EndPageOnSameToken = true
+
+	assert.Equal(t, []string{"a", "b"}, collectPages(p))
+	assert.NoError(t, p.Err())
+	assert.Equal(t, []string{"", "t1"}, *tokens)
+}
+
+func TestPaginationError(t *testing.T) {
+	s, _ := newPaginationClient(mockPages)
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		if r.Params.(*mockListInput).NextToken != nil {
+			r.Error = awserr.New("MockError", "mock error", nil)
+		}
+	})
+	p := newPagination(s)
+	p.Prefetch = true
+
+	assert.Equal(t, []string{"a", "b"}, collectPages(p))
+	assert.Error(t, p.Err())
+	assert.Equal(t, "MockError", p.Err().(awserr.Error).Code())
+}
@@ -2,9 +2,8 @@ package request
 
 import (
 	"bytes"
-	"fmt"
+	"context"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -34,14 +33,28 @@ type Request struct {
 	Error            error
 	Data             interface{}
 	RequestID        string
+	HostID           string
 	RetryCount       int
 	Retryable        *bool
 	RetryDelay       time.Duration
 	NotHoist         bool
 	SignedHeaderVals http.Header
 	LastSignedAt     time.Time
+	ClockSkewRetried bool
+	Hedging          *HedgingPolicy
+	HandlerTimings   []HandlerTiming
 
-	built bool
+	// AttemptTime is the time the current attempt to send the request
+	// started at.
+	AttemptTime time.Time
+
+	// CredentialProvider is the name of the provider of the credentials
+	// the request was last signed with.
+	CredentialProvider string
+
+	context       context.Context
+	built         bool
+	streamingBody *streamingBody
 }
 
 // An Operation is the service API operation to be made.
@@ -50,6 +63,27 @@ type Operation struct {
 	HTTPMethod string
 	HTTPPath   string
 	*Paginator
+
+	// Idempotent marks operations which can safely be sent more than once
+	// concurrently, such as reads. Only idempotent operations are hedged.
+	Idempotent bool
+
+	// HTTPChecksumRequired marks operations whose request body must be sent
+	// with a Content-MD5 checksum.
+	HTTPChecksumRequired bool
+
+	// RequestChecksumAlgorithm is the algorithm of the checksum sent with
+	// the request body in the X-Aws-Checksum-<algorithm> header, e.g.
+	// "CRC32". No checksum is sent if empty.
+	RequestChecksumAlgorithm string
+
+	// ResponseChecksumValidation enables validating the response body
+	// against the X-Aws-Checksum-<algorithm> header returned by the service.
+	ResponseChecksumValidation bool
+
+	// RequestCompression marks operations which accept request bodies
+	// compressed with gzip.
+	RequestCompression bool
 }
 
 // Paginator keeps track of pagination configuration for an API operation.
@@ -102,9 +136,22 @@ func New(cfg service.Config, clientInfo metadata.ClientInfo, handlers Handlers,
 	return r
 }
 
-// WillRetry returns if the request's can be retried.
+// NewWithContext returns a new Request pointer for the service API operation
+// and parameters, using ctx as the request's Context. See New for details of
+// the params and data values.
+func NewWithContext(ctx context.Context, cfg service.Config, clientInfo metadata.ClientInfo, handlers Handlers,
+	retryer Retryer, operation *Operation, params interface{}, data interface{}) *Request {
+	r := New(cfg, clientInfo, handlers, retryer, operation, params, data)
+	r.SetContext(ctx)
+
+	return r
+}
+
+// WillRetry returns if the request's can be retried. A request with a
+// streaming body can not be retried once its body has been read.
 func (r *Request) WillRetry() bool {
-	return r.Error != nil && service.BoolValue(r.Retryable) && r.RetryCount < r.MaxRetries()
+	return r.Error != nil && service.BoolValue(r.Retryable) && r.RetryCount < r.MaxRetries() &&
+		r.bodyRewindable()
 }
 
 // ParamsFilled returns if the request's parameters have been populated
@@ -121,6 +168,31 @@ func (r *Request) DataFilled() bool {
 	return r.Data != nil && reflect.ValueOf(r.Data).Elem().IsValid()
 }
 
+// ResetBody rewinds the request's body to BodyStart, and sets it as the body
+// of the HTTP request. The HTTP request's previous body is closed, so an
+// attempt still reading it, such as after the service rejected the request
+// before reading the body, cannot read the rewound body.
+//
+// A streaming body is not rewound, see SetStreamingBody.
+func (r *Request) ResetBody() {
+	if r.streamingBody != nil {
+		r.HTTPRequest.Body = r.streamingBody
+		return
+	}
+
+	if reader, ok := r.HTTPRequest.Body.(*offsetReader); ok {
+		r.HTTPRequest.Body = reader.CloseAndCopy(r.BodyStart)
+		return
+	}
+
+	if r.Body == nil {
+		r.HTTPRequest.Body = nil
+		return
+	}
+
+	r.HTTPRequest.Body = newOffsetReader(r.Body, r.BodyStart)
+}
+
 // SetBufferBody will set the request's body bytes that will be sent to
 // the service API.
 func (r *Request) SetBufferBody(buf []byte) {
@@ -136,6 +208,7 @@ func (r *Request) SetStringBody(s string) {
 func (r *Request) SetReaderBody(reader io.ReadSeeker) {
 	r.HTTPRequest.Body = newOffsetReader(reader, 0)
 	r.Body = reader
+	r.streamingBody = nil
 }
 
 // Presign returns the request's signed URL. Error will be returned
@@ -169,13 +242,31 @@ func debugLogReqError(r *Request, stage string, retrying bool, err error) {
 		return
 	}
 
-	retryStr := "not retrying"
-	if retrying {
-		retryStr = "will retry"
+	r.Config.LogFields(service.SeverityDebug, "Request failed",
+		r.LogContext("stage", stage, "retrying", retrying, "error", err)...)
+}
+
+// LogContext returns the key/value fields identifying the request in
+// structured log entries, followed by the additional key/value fields.
+// The fields are the service, operation, attempt, and the status code,
+// request ID and host ID of the response, once known.
+func (r *Request) LogContext(keyvals ...interface{}) []interface{} {
+	fields := []interface{}{
+		"service", r.ClientInfo.ServiceName,
+		"operation", r.Operation.Name,
+		"attempt", r.RetryCount + 1,
+	}
+	if r.HTTPResponse != nil && r.HTTPResponse.StatusCode != 0 {
+		fields = append(fields, "status", r.HTTPResponse.StatusCode)
+	}
+	if r.RequestID != "" {
+		fields = append(fields, "request_id", r.RequestID)
+	}
+	if r.HostID != "" {
+		fields = append(fields, "host_id", r.HostID)
 	}
 
-	r.Config.Logger.Log(fmt.Sprintf("DEBUG: %s %s/%s failed, %s, error %v",
-		stage, r.ClientInfo.ServiceName, r.Operation.Name, retryStr, err))
+	return append(fields, keyvals...)
 }
 
 // Build will build the request's object so it can be signed and sent
@@ -228,33 +319,34 @@ func (r *Request) Sign() error {
 // Send will sign the request prior to sending. All Send Handlers will
 // be executed in the order they were set.
 //
-// Canceling a request is non-deterministic. If a request has been canceled,
-// then the transport will choose, randomly, one of the state channels during
-// reads or getting the connection.
+// The CompleteAttempt handlers are run once each attempt to send the request
+// completes, before the request is retried, and the Complete handlers once
+// the request completes, whether successful or not.
 //
-// readLoop() and getConn(req *Request, cm connectMethod)
-// https://github.com/golang/go/blob/master/src/net/http/transport.go
+// A request is canceled by canceling the Context set with SetContext, or by
+// closing the http.Request's Cancel channel. A canceled request is not
+// retried and returns an error with the CanceledErrCode code.
 func (r *Request) Send() error {
+	defer r.Handlers.Complete.Run(r)
+
 	for {
 		if service.BoolValue(r.Retryable) {
-			if r.Config.LogLevel.Matches(service.LogDebugWithRequestRetries) {
-				r.Config.Logger.Log(fmt.Sprintf("DEBUG: Retrying Request %s/%s, attempt %d",
-					r.ClientInfo.ServiceName, r.Operation.Name, r.RetryCount))
+			if r.setErrorIfCanceled(nil) {
+				debugLogReqError(r, "Retry Request", false, r.Error)
+				return r.Error
 			}
 
-			var body io.ReadCloser
-			if reader, ok := r.HTTPRequest.Body.(*offsetReader); ok {
-				body = reader.CloseAndCopy(r.BodyStart)
-			} else {
-				if r.Config.Logger != nil {
-					r.Config.Logger.Log("Request body type has been overwritten. May cause race conditions")
-				}
+			if r.Config.LogLevel.Matches(service.LogDebugWithRequestRetries) {
+				r.Config.LogFields(service.SeverityDebug, "Retrying request", r.LogContext()...)
+			}
 
-				r.Body.Seek(r.BodyStart, 0)
-				body = ioutil.NopCloser(r.Body)
+			if _, ok := r.HTTPRequest.Body.(*offsetReader); !ok && r.streamingBody == nil {
+				r.Config.LogFields(service.SeverityWarn,
+					"Request body type has been overwritten. May cause race conditions", r.LogContext()...)
 			}
 
-			r.HTTPRequest = copyHTTPRequest(r.HTTPRequest, body)
+			r.HTTPRequest = copyHTTPRequest(r.HTTPRequest, r.HTTPRequest.Body)
+			r.ResetBody()
 			if r.HTTPResponse != nil && r.HTTPResponse.Body != nil {
 				// Closing response body. Since we are setting a new request to send off, this
 				// response will get squashed and leaked.
@@ -262,6 +354,8 @@ func (r *Request) Send() error {
 			}
 		}
 
+		r.AttemptTime = time.Now()
+
 		r.Sign()
 		if r.Error != nil {
 			return r.Error
@@ -269,58 +363,55 @@ func (r *Request) Send() error {
 
 		r.Retryable = nil
 
-		r.Handlers.Send.Run(r)
-		if r.Error != nil {
-			if strings.Contains(r.Error.Error(), "net/http: request canceled") {
-				return r.Error
-			}
-
-			err := r.Error
-			r.Handlers.Retry.Run(r)
-			r.Handlers.AfterRetry.Run(r)
-			if r.Error != nil {
-				debugLogReqError(r, "Send Request", false, r.Error)
-				return r.Error
-			}
-
-			debugLogReqError(r, "Send Request", true, err)
-			continue
+		stage, canceled := r.sendAttempt()
+		if r.Error == nil {
+			return nil
+		}
+		if canceled {
+			debugLogReqError(r, stage, false, r.Error)
+			return r.Error
 		}
 
-		r.Handlers.UnmarshalMeta.Run(r)
-		r.Handlers.ValidateResponse.Run(r)
+		err := r.Error
+		r.Handlers.Retry.Run(r)
+		r.Handlers.AfterRetry.Run(r)
 		if r.Error != nil {
-			err := r.Error
-			r.Handlers.UnmarshalError.Run(r)
-			r.Handlers.Retry.Run(r)
-			r.Handlers.AfterRetry.Run(r)
-			if r.Error != nil {
-				debugLogReqError(r, "Validate Response", false, r.Error)
-				return r.Error
-			}
-
-			debugLogReqError(r, "Validate Response", true, err)
-			continue
+			debugLogReqError(r, stage, false, r.Error)
+			return r.Error
 		}
 
-		r.Handlers.Unmarshal.Run(r)
-		if r.Error != nil {
-			err := r.Error
-			r.Handlers.Retry.Run(r)
-			r.Handlers.AfterRetry.Run(r)
-			if r.Error != nil {
-				debugLogReqError(r, "Unmarshal Response", false, r.Error)
-				return r.Error
-			}
+		debugLogReqError(r, stage, true, err)
+	}
+}
 
-			debugLogReqError(r, "Unmarshal Response", true, err)
-			continue
-		}
+// sendAttempt sends the signed request once, and validates and unmarshals
+// its response. The stage the attempt failed at is returned if it failed,
+// and whether it failed because the request was canceled.
+func (r *Request) sendAttempt() (stage string, canceled bool) {
+	defer r.Handlers.CompleteAttempt.Run(r)
 
-		break
+	if r.Hedging != nil && r.Operation.Idempotent {
+		r.sendHedged()
+	} else {
+		r.Handlers.Send.Run(r)
+	}
+	if r.Error != nil {
+		return "Send Request", r.setErrorIfCanceled(r.Error)
+	}
+
+	r.Handlers.UnmarshalMeta.Run(r)
+	r.Handlers.ValidateResponse.Run(r)
+	if r.Error != nil {
+		r.Handlers.UnmarshalError.Run(r)
+		return "Validate Response", false
+	}
+
+	r.Handlers.Unmarshal.Run(r)
+	if r.Error != nil {
+		return "Unmarshal Response", false
 	}
 
-	return nil
+	return "", false
 }
 
 // AddToUserAgent adds the string to the end of the request's current user agent.
@@ -47,7 +47,7 @@ func (r *Request) nextPageTokens() []interface{} {
 	tokenAdded := false
 	for _, outToken := range r.Operation.OutputTokens {
 		v, _ := awsutil.ValuesAtPath(r.Data, outToken)
-		if len(v) > 0 {
+		if len(v) > 0 && !isEmptyPageToken(v[0]) {
 			tokens = append(tokens, v[0])
 			tokenAdded = true
 		} else {
@@ -61,6 +61,22 @@ func (r *Request) nextPageTokens() []interface{} {
 	return tokens
 }
 
+// isEmptyPageToken returns true if the page token value is empty, such as an
+// empty string or map, and so does not point to another page of data.
+func isEmptyPageToken(v interface{}) bool {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return true
+	}
+
+	switch rv.Kind() {
+	case reflect.String, reflect.Map, reflect.Slice:
+		return rv.Len() == 0
+	}
+
+	return false
+}
+
 // NextPage returns a new Request that can be executed to return the next
 // page of result data. Call .Send() on this request to execute it.
 func (r *Request) NextPage() *Request {
@@ -93,6 +93,49 @@ func TestRequestRecoverRetry5xx(t *testing.T) {
 	assert.Equal(t, "valid", out.Data)
 }
 
+// test that CompleteAttempt handlers run once per attempt, and Complete
+// handlers once per request
+func TestRequestCompleteHandlers(t *testing.T) {
+	reqNum := 0
+	reqs := []http.Response{
+		{StatusCode: 500, Body: body(`{"__type":"UnknownError","message":"An error occurred."}`)},
+		{StatusCode: 200, Body: body(`{"data":"valid"}`)},
+	}
+
+	s := awstesting.NewClient(service.NewConfig().WithMaxRetries(10))
+	s.Handlers.Validate.Clear()
+	s.Handlers.Unmarshal.PushBack(unmarshal)
+	s.Handlers.UnmarshalError.PushBack(unmarshalError)
+	s.Handlers.Send.Clear() // mock sending
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &reqs[reqNum]
+		reqNum++
+	})
+
+	var attempts []string
+	s.Handlers.CompleteAttempt.PushBack(func(r *request.Request) {
+		assert.False(t, r.AttemptTime.IsZero())
+		if r.Error != nil {
+			attempts = append(attempts, r.Error.(awserr.Error).Code())
+		} else {
+			attempts = append(attempts, "")
+		}
+	})
+
+	completed := 0
+	s.Handlers.Complete.PushBack(func(r *request.Request) {
+		completed++
+		assert.Nil(t, r.Error)
+		assert.Equal(t, 1, r.RetryCount)
+	})
+
+	r := s.NewRequest(&request.Operation{Name: "Operation"}, nil, &testData{})
+	err := r.Send()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"UnknownError", ""}, attempts)
+	assert.Equal(t, 1, completed)
+}
+
 // test that retries occur for 4xx status codes with a response type that can be retried - see `shouldRetry`
 func TestRequestRecoverRetry4xxRetryable(t *testing.T) {
 	reqNum := 0
@@ -26,8 +26,9 @@ func WithRetryer(cfg *service.Config, retryer Retryer) *service.Config {
 // retryableCodes is a collection of service response codes which are retry-able
 // without any further action.
 var retryableCodes = map[string]struct{}{
-	"RequestError":   {},
-	"RequestTimeout": {},
+	"RequestError":         {},
+	"RequestTimeout":       {},
+	ResponseTimeoutErrCode: {}, // Read timeout set by WithResponseReadTimeout
 }
 
 var throttleCodes = map[string]struct{}{
@@ -49,6 +50,15 @@ var credsExpiredCodes = map[string]struct{}{
 	"RequestExpired":        {}, // EC2 Only
 }
 
+// clockSkewCodes is a collection of error codes which signify the request was
+// rejected because the local clock differs from the service's clock. These
+// requests need resigning with the corrected time before they can be retried.
+var clockSkewCodes = map[string]struct{}{
+	"RequestTimeTooSkewed":      {},
+	"RequestInTheFuture":        {},
+	"InvalidSignatureException": {},
+}
+
 func isCodeThrottle(code string) bool {
 	_, ok := throttleCodes[code]
 	return ok
@@ -59,7 +69,7 @@ func isCodeRetryable(code string) bool {
 		return true
 	}
 
-	return isCodeExpiredCreds(code)
+	return isCodeExpiredCreds(code) || isCodeClockSkew(code)
 }
 
 func isCodeExpiredCreds(code string) bool {
@@ -67,6 +77,26 @@ func isCodeExpiredCreds(code string) bool {
 	return ok
 }
 
+func isCodeClockSkew(code string) bool {
+	_, ok := clockSkewCodes[code]
+	return ok
+}
+
+// ResolveRetryable sets the request's Retryable with the request's
+// ShouldRetry, unless one of the handlers already set it. Clock skew errors
+// are only worth retrying once, after the request has been resigned with the
+// corrected time.
+func (r *Request) ResolveRetryable() {
+	if r.Retryable != nil {
+		return
+	}
+
+	r.Retryable = service.Bool(r.ShouldRetry(r))
+	if r.IsErrorClockSkew() {
+		r.Retryable = service.Bool(!r.ClockSkewRetried)
+	}
+}
+
 // IsErrorRetryable returns whether the error is retryable, based on its Code.
 // Returns false if the request has no Error set.
 func (r *Request) IsErrorRetryable() bool {
@@ -99,3 +129,14 @@ func (r *Request) IsErrorExpired() bool {
 	}
 	return false
 }
+
+// IsErrorClockSkew returns whether the error code is a clock skew error.
+// Returns false if the request has no Error set.
+func (r *Request) IsErrorClockSkew() bool {
+	if r.Error != nil {
+		if err, ok := r.Error.(awserr.Error); ok {
+			return isCodeClockSkew(err.Code())
+		}
+	}
+	return false
+}
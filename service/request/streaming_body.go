@@ -0,0 +1,88 @@
+package request
+
+import (
+	"io"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/golib/aws/service"
+)
+
+const (
+	// UnknownBodyLengthErrCode is the error code for a request whose body
+	// length cannot be determined, such as a body which fails to seek.
+	UnknownBodyLengthErrCode = "UnknownBodyLength"
+)
+
+// streamingBody is a request body which cannot be seeked, recording if any
+// of it has been read. The underlying reader is never closed by the SDK.
+type streamingBody struct {
+	reader io.Reader
+	read   int32
+}
+
+// Read reads from the underlying reader, recording if any bytes were read.
+func (b *streamingBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	if n > 0 || err != nil {
+		atomic.StoreInt32(&b.read, 1)
+	}
+	return n, err
+}
+
+// Close does not close the underlying reader, as the HTTP client closes
+// request bodies after each attempt, including failed attempts which did
+// not read the body.
+func (b *streamingBody) Close() error {
+	return nil
+}
+
+// consumed returns if any of the body has been read.
+func (b *streamingBody) consumed() bool {
+	return atomic.LoadInt32(&b.read) == 1
+}
+
+// SetStreamingBody sets the request's body to a reader which is streamed to
+// the service as it is sent, instead of being buffered in memory. If the
+// reader is an io.ReadSeeker the body is set with SetReaderBody instead.
+//
+// The length is the number of bytes the reader will return, and is sent as
+// the request's Content-Length. If the length is negative it is unknown, and
+// the body will be sent with chunked transfer encoding.
+//
+// A streaming body cannot be rewound. The request is only retried if the
+// failed attempt did not read from the body, and the body's payload is not
+// included in the request's signature. The caller is responsible for closing
+// the reader once the request completes.
+func (r *Request) SetStreamingBody(reader io.Reader, length int64) {
+	if rs, ok := reader.(io.ReadSeeker); ok {
+		r.SetReaderBody(rs)
+		return
+	}
+
+	body := &streamingBody{reader: reader}
+	r.streamingBody = body
+	r.Body = service.ReadSeekCloser(body)
+	r.BodyStart = 0
+	r.HTTPRequest.Body = body
+
+	if length >= 0 {
+		r.HTTPRequest.ContentLength = length
+		r.HTTPRequest.Header.Set("Content-Length", strconv.FormatInt(length, 10))
+	} else {
+		r.HTTPRequest.ContentLength = -1
+		r.HTTPRequest.Header.Del("Content-Length")
+	}
+}
+
+// IsBodySeekable returns if the request's body can be seeked, and rewound
+// to be retried. Bodies set with SetStreamingBody are not seekable.
+func (r *Request) IsBodySeekable() bool {
+	return r.streamingBody == nil && (r.Body == nil || service.IsReaderSeekable(r.Body))
+}
+
+// bodyRewindable returns if the request's body can be sent again. A
+// streaming body can only be sent again if none of it has been read.
+func (r *Request) bodyRewindable() bool {
+	return r.streamingBody == nil || !r.streamingBody.consumed()
+}
@@ -0,0 +1,167 @@
+package request_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/client"
+	"github.com/golib/aws/service/request"
+)
+
+// nonSeekableReader hides the io.Seeker of the reader it wraps.
+type nonSeekableReader struct {
+	io.Reader
+}
+
+// newStreamingClient returns a client whose Send handler reads the request's
+// body, responding with the status codes in order.
+func newStreamingClient(bodies *[]string, statusCodes ...int) *client.Client {
+	s := awstesting.NewClient(service.NewConfig().WithMaxRetries(3))
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		b, err := ioutil.ReadAll(r.HTTPRequest.Body)
+		if err != nil {
+			r.Error = err
+			return
+		}
+		*bodies = append(*bodies, string(b))
+
+		code := statusCodes[len(*bodies)-1]
+		r.HTTPResponse = &http.Response{
+			StatusCode: code,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		}
+	})
+	s.Handlers.UnmarshalError.PushBack(func(r *request.Request) {
+		r.Error = awserr.New("ServiceUnavailable", "service unavailable", nil)
+	})
+
+	return s
+}
+
+func TestStreamingBodyUnknownLength(t *testing.T) {
+	var bodies []string
+	s := newStreamingClient(&bodies, 200)
+
+	r := s.NewRequest(&request.Operation{Name: "Upload", HTTPMethod: "PUT"}, nil, nil)
+	r.SetStreamingBody(nonSeekableReader{strings.NewReader("streaming body")}, -1)
+	assert.False(t, r.IsBodySeekable())
+
+	assert.NoError(t, r.Build())
+	assert.Equal(t, int64(-1), r.HTTPRequest.ContentLength)
+	assert.Empty(t, r.HTTPRequest.Header.Get("Content-Length"))
+
+	assert.NoError(t, r.Send())
+	assert.Equal(t, []string{"streaming body"}, bodies)
+}
+
+func TestStreamingBodyKnownLength(t *testing.T) {
+	var bodies []string
+	s := newStreamingClient(&bodies, 200)
+
+	r := s.NewRequest(&request.Operation{Name: "Upload", HTTPMethod: "PUT"}, nil, nil)
+	r.SetStreamingBody(nonSeekableReader{strings.NewReader("streaming body")}, 14)
+
+	assert.NoError(t, r.Build())
+	assert.Equal(t, int64(14), r.HTTPRequest.ContentLength)
+	assert.Equal(t, "14", r.HTTPRequest.Header.Get("Content-Length"))
+
+	assert.NoError(t, r.Send())
+	assert.Equal(t, []string{"streaming body"}, bodies)
+}
+
+func TestStreamingBodyNotRetriedOnceRead(t *testing.T) {
+	var bodies []string
+	s := newStreamingClient(&bodies, 503, 200)
+
+	r := s.NewRequest(&request.Operation{Name: "Upload", HTTPMethod: "PUT"}, nil, nil)
+	r.SetStreamingBody(nonSeekableReader{strings.NewReader("streaming body")}, -1)
+
+	err := r.Send()
+	assert.Error(t, err)
+	assert.Equal(t, "ServiceUnavailable", err.(awserr.Error).Code())
+	assert.Equal(t, 0, r.RetryCount)
+	assert.Equal(t, []string{"streaming body"}, bodies)
+}
+
+func TestStreamingBodyRetriedWhenNotRead(t *testing.T) {
+	s := awstesting.NewClient(service.NewConfig().WithMaxRetries(3))
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+
+	var bodies []string
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		if r.RetryCount == 0 {
+			// Fail before the body is sent, e.g. a failed dial.
+			r.HTTPRequest.Body.Close()
+			r.Error = awserr.New("RequestError", "send request failed", errors.New("dial failed"))
+			r.Retryable = service.Bool(true)
+			r.HTTPResponse = &http.Response{
+				Header: http.Header{},
+				Body:   ioutil.NopCloser(bytes.NewReader(nil)),
+			}
+			return
+		}
+
+		b, _ := ioutil.ReadAll(r.HTTPRequest.Body)
+		bodies = append(bodies, string(b))
+		r.HTTPResponse = &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		}
+	})
+
+	r := s.NewRequest(&request.Operation{Name: "Upload", HTTPMethod: "PUT"}, nil, nil)
+	r.SetStreamingBody(nonSeekableReader{strings.NewReader("streaming body")}, -1)
+
+	assert.NoError(t, r.Send())
+	assert.Equal(t, 1, r.RetryCount)
+	assert.Equal(t, []string{"streaming body"}, bodies)
+}
+
+func TestStreamingBodySeekableReader(t *testing.T) {
+	var bodies []string
+	s := newStreamingClient(&bodies, 503, 200)
+
+	r := s.NewRequest(&request.Operation{Name: "Upload", HTTPMethod: "PUT"}, nil, nil)
+	r.SetStreamingBody(strings.NewReader("seekable body"), -1)
+	assert.True(t, r.IsBodySeekable())
+
+	assert.NoError(t, r.Send())
+	assert.Equal(t, 1, r.RetryCount)
+	assert.Equal(t, []string{"seekable body", "seekable body"}, bodies)
+}
+
+// failingSeeker is a body whose Seek always fails, such as a pipe.
+type failingSeeker struct {
+	io.Reader
+}
+
+func (failingSeeker) Seek(int64, int) (int64, error) {
+	return 0, errors.New("illegal seek")
+}
+
+func TestBodySeekErrorFailsRequest(t *testing.T) {
+	var bodies []string
+	s := newStreamingClient(&bodies, 200)
+
+	r := s.NewRequest(&request.Operation{Name: "Upload", HTTPMethod: "PUT"}, nil, nil)
+	r.SetReaderBody(failingSeeker{strings.NewReader("body")})
+
+	err := r.Send()
+	assert.Error(t, err)
+	assert.Equal(t, request.UnknownBodyLengthErrCode, err.(awserr.Error).Code())
+	assert.Empty(t, bodies)
+}
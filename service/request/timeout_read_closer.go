@@ -0,0 +1,71 @@
+package request
+
+import (
+	"io"
+	"time"
+
+	"github.com/golib/aws/service/awserr"
+)
+
+const (
+	// ResponseTimeoutErrCode is the error code returned when reading the
+	// response body takes longer than the duration set by the
+	// WithResponseReadTimeout request option.
+	ResponseTimeoutErrCode = "ResponseTimeout"
+)
+
+var timeoutErr = awserr.New(
+	ResponseTimeoutErrCode,
+	"read on body has reached the timeout limit",
+	nil,
+)
+
+type readResult struct {
+	n   int
+	err error
+}
+
+// timeoutReadCloser will handle body reads that take too long.
+// We will return a ResponseTimeoutErrCode error if a timeout occurs.
+type timeoutReadCloser struct {
+	reader   io.ReadCloser
+	duration time.Duration
+}
+
+// Read will spin off a goroutine to call the reader's Read method. We will
+// select on the timer's channel or the read's channel. Whoever completes
+// first will be returned.
+func (r *timeoutReadCloser) Read(b []byte) (int, error) {
+	timer := time.NewTimer(r.duration)
+	c := make(chan readResult, 1)
+
+	go func() {
+		n, err := r.reader.Read(b)
+		timer.Stop()
+		c <- readResult{n: n, err: err}
+	}()
+
+	select {
+	case data := <-c:
+		return data.n, data.err
+	case <-timer.C:
+		return 0, timeoutErr
+	}
+}
+
+// Close closes the underlying reader.
+func (r *timeoutReadCloser) Close() error {
+	return r.reader.Close()
+}
+
+// adaptToResponseTimeoutError is a handler that will replace any top level
+// error with a ResponseTimeoutErrCode error, if the top level error's
+// original error is the response timeout error. Unmarshalers wrap read
+// errors, so this allows the timeout to be recognized and retried.
+func adaptToResponseTimeoutError(r *Request) {
+	if err, ok := r.Error.(awserr.Error); ok {
+		if err.Code() != ResponseTimeoutErrCode && err.OrigErr() == timeoutErr {
+			r.Error = timeoutErr
+		}
+	}
+}
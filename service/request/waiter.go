@@ -0,0 +1,304 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awsutil"
+)
+
+const (
+	// WaiterResourceNotReadyErrCode is the error code returned by a waiter
+	// when the resource did not reach the expected state within the max
+	// attempts, or reached a failure state.
+	WaiterResourceNotReadyErrCode = "ResourceNotReady"
+)
+
+// A WaiterOption is a function that will update the Waiter value's fields
+// to configure the waiter.
+type WaiterOption func(*Waiter)
+
+// WithWaiterMaxAttempts returns the maximum number of times the waiter
+// should attempt to check the resource for the target state.
+func WithWaiterMaxAttempts(max int) WaiterOption {
+	return func(w *Waiter) {
+		w.MaxAttempts = max
+	}
+}
+
+// WaiterDelay will return a delay the waiter should pause between attempts
+// to check the resource state. The passed in attempt is the number of times
+// the Waiter has checked the resource state, starting from 1.
+type WaiterDelay func(attempt int) time.Duration
+
+// ConstantWaiterDelay returns a WaiterDelay that will always return a
+// constant delay the waiter should use between attempts.
+func ConstantWaiterDelay(delay time.Duration) WaiterDelay {
+	return func(attempt int) time.Duration {
+		return delay
+	}
+}
+
+// WithWaiterDelay will set the Waiter to use the WaiterDelay passed in.
+func WithWaiterDelay(delayer WaiterDelay) WaiterOption {
+	return func(w *Waiter) {
+		w.Delay = delayer
+	}
+}
+
+// WithWaiterLogger returns a waiter option to set the logger a waiter
+// should use to log warnings and errors to.
+func WithWaiterLogger(logger service.Logger) WaiterOption {
+	return func(w *Waiter) {
+		w.Logger = logger
+	}
+}
+
+// WithWaiterRequestOptions returns a waiter option setting the request
+// options for each request the Waiter makes. Appends to waiter's request
+// options already set.
+func WithWaiterRequestOptions(opts ...Option) WaiterOption {
+	return func(w *Waiter) {
+		w.RequestOptions = append(w.RequestOptions, opts...)
+	}
+}
+
+// A Waiter provides the functionality to perform a blocking call which will
+// wait for a resource state to be satisfied by a service.
+//
+// Each attempt creates a new request with NewRequest, sends it, and compares
+// the response against the Acceptors. Service clients should wrap a Waiter
+// in a "WaitUntil" prefixed method, e.g. WaitUntilBucketExists.
+type Waiter struct {
+	Name      string
+	Acceptors []WaiterAcceptor
+	Logger    service.Logger
+
+	MaxAttempts int
+	Delay       WaiterDelay
+
+	RequestOptions   []Option
+	NewRequest       func([]Option) (*Request, error)
+	SleepWithContext func(context.Context, time.Duration) error
+}
+
+// ApplyOptions updates the waiter with the list of waiter options provided.
+func (w *Waiter) ApplyOptions(opts ...WaiterOption) {
+	for _, fn := range opts {
+		fn(w)
+	}
+}
+
+// WaiterState are states the waiter uses based on WaiterAcceptor definitions
+// to identify if the resource state the waiter is waiting on has occurred.
+type WaiterState int
+
+// String returns the string representation of the waiter state.
+func (s WaiterState) String() string {
+	switch s {
+	case SuccessWaiterState:
+		return "success"
+	case FailureWaiterState:
+		return "failure"
+	case RetryWaiterState:
+		return "retry"
+	default:
+		return "unknown waiter state"
+	}
+}
+
+// States the waiter acceptors will use to identify target resource states.
+const (
+	SuccessWaiterState WaiterState = iota // waiter successful
+	FailureWaiterState                    // waiter failed
+	RetryWaiterState                      // waiter needs to be retried
+)
+
+// WaiterMatchMode is the mode that the waiter will use to match the
+// WaiterAcceptor definition's Expected attribute.
+type WaiterMatchMode int
+
+// Modes the waiter will use when inspecting API response to identify target
+// resource states.
+const (
+	PathAllWaiterMatch WaiterMatchMode = iota // match on all paths
+	PathWaiterMatch                           // match on specific path
+	PathAnyWaiterMatch                        // match on any path
+	StatusWaiterMatch                         // match on status code
+	ErrorWaiterMatch                          // match on error
+)
+
+// String returns the string representation of the waiter match mode.
+func (m WaiterMatchMode) String() string {
+	switch m {
+	case PathAllWaiterMatch:
+		return "pathAll"
+	case PathWaiterMatch:
+		return "path"
+	case PathAnyWaiterMatch:
+		return "pathAny"
+	case StatusWaiterMatch:
+		return "status"
+	case ErrorWaiterMatch:
+		return "error"
+	default:
+		return "unknown waiter match mode"
+	}
+}
+
+// Wait will make requests for the API operation using NewRequest to build
+// API requests. The request's response will be compared against the
+// Waiter's Acceptors to determine the successful state of the resource the
+// waiter is inspecting.
+func (w Waiter) Wait() error {
+	return w.WaitWithContext(context.Background())
+}
+
+// WaitWithContext will make requests for the API operation using NewRequest
+// to build API requests. The request's response will be compared against
+// the Waiter's Acceptors to determine the successful state of the resource
+// the waiter is inspecting.
+//
+// The passed in context must not be nil. If it is nil a panic will occur.
+// The context will be used to cancel the waiter's pending requests and
+// retry delays. Use context.Background for no cancellation.
+//
+// The waiter will continue until the target state defined by the Acceptors,
+// or the max attempts expires.
+//
+// Will return the WaiterResourceNotReadyErrCode error code if the max wait
+// attempts expires, or an acceptor matched a failure state. A request error
+// not matched by any acceptor is returned as is.
+func (w Waiter) WaitWithContext(ctx context.Context) error {
+	sleepFn := w.SleepWithContext
+	if sleepFn == nil {
+		sleepFn = SleepWithContext
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := w.NewRequest(w.RequestOptions)
+		if err != nil {
+			waiterLogf(w.Logger, "unable to create request %v", err)
+			return err
+		}
+		req.SetContext(ctx)
+		req.Handlers.Build.PushBack(MakeAddToUserAgentFreeFormHandler("Waiter"))
+		err = req.Send()
+
+		// See if any of the acceptors match the request's response, or error
+		if a := w.matchAcceptor(req, err); a != nil {
+			switch a.State {
+			case SuccessWaiterState:
+				return nil
+			case FailureWaiterState:
+				return awserr.New(WaiterResourceNotReadyErrCode,
+					"failed waiting for successful resource state", err)
+			}
+		} else if err != nil {
+			// Errors not expected by any acceptor can not be waited out.
+			return err
+		}
+
+		// The Waiter should only check the resource state MaxAttempts times.
+		// This is here instead of in the for loop above to prevent delaying
+		// unnecessary when the waiter will not retry.
+		if attempt >= w.MaxAttempts {
+			break
+		}
+
+		// Delay to wait before inspecting the resource again
+		delay := time.Duration(0)
+		if w.Delay != nil {
+			delay = w.Delay(attempt)
+		}
+
+		if err := sleepFn(ctx, delay); err != nil {
+			return awserr.New(CanceledErrCode, "waiter context canceled", err)
+		}
+	}
+
+	return awserr.New(WaiterResourceNotReadyErrCode, "exceeded wait attempts", nil)
+}
+
+// A WaiterAcceptor provides the information needed to wait for an API
+// operation to complete.
+//
+// The Argument of path matchers is an expression evaluated against the
+// request's output Data with awsutil.ValuesAtPath.
+type WaiterAcceptor struct {
+	State    WaiterState
+	Matcher  WaiterMatchMode
+	Argument string
+	Expected interface{}
+}
+
+// matchAcceptor returns the first of the waiter's acceptors matching the
+// request's response or error. Nil is returned if no acceptor matched.
+func (w Waiter) matchAcceptor(req *Request, err error) *WaiterAcceptor {
+	for i := range w.Acceptors {
+		a := &w.Acceptors[i]
+		if a.match(w.Name, w.Logger, req, err) {
+			return a
+		}
+	}
+
+	return nil
+}
+
+// match returns true if the acceptor's expected value matches the request's
+// response or error.
+func (a *WaiterAcceptor) match(name string, l service.Logger, req *Request, err error) bool {
+	switch a.Matcher {
+	case PathAllWaiterMatch, PathWaiterMatch:
+		if err != nil {
+			return false
+		}
+
+		// Require all matches to be equal for result to match
+		vals, _ := awsutil.ValuesAtPath(req.Data, a.Argument)
+		if len(vals) == 0 {
+			return false
+		}
+		for _, val := range vals {
+			if !awsutil.DeepEqual(val, a.Expected) {
+				return false
+			}
+		}
+		return true
+	case PathAnyWaiterMatch:
+		if err != nil {
+			return false
+		}
+
+		// Only a single match needs to equal for the result to match
+		vals, _ := awsutil.ValuesAtPath(req.Data, a.Argument)
+		for _, val := range vals {
+			if awsutil.DeepEqual(val, a.Expected) {
+				return true
+			}
+		}
+		return false
+	case StatusWaiterMatch:
+		s, ok := a.Expected.(int)
+		return ok && req.HTTPResponse != nil && s == req.HTTPResponse.StatusCode
+	case ErrorWaiterMatch:
+		code, ok := a.Expected.(string)
+		if aerr, isAWSErr := err.(awserr.Error); ok && isAWSErr {
+			return aerr.Code() == code
+		}
+		return false
+	default:
+		waiterLogf(l, "WARNING: Waiter %s encountered unexpected matcher: %s",
+			name, a.Matcher)
+		return false
+	}
+}
+
+func waiterLogf(logger service.Logger, msg string, args ...interface{}) {
+	if logger != nil {
+		logger.Log(fmt.Sprintf(msg, args...))
+	}
+}
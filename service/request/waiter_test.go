@@ -0,0 +1,167 @@
+package request_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/client"
+	"github.com/golib/aws/service/request"
+)
+
+type mockInstance struct {
+	State *string
+}
+
+type mockDescribeOutput struct {
+	Instances []*mockInstance
+}
+
+func newWaiterClient(states [][]string, statusCodes []int) (*client.Client, *int) {
+	reqNum := 0
+	s := awstesting.NewClient()
+	s.Handlers.Validate.Clear()
+	s.Handlers.Send.Clear()
+	s.Handlers.Send.PushBack(func(r *request.Request) {
+		code := 200
+		if reqNum < len(statusCodes) {
+			code = statusCodes[reqNum]
+		}
+		r.HTTPResponse = &http.Response{StatusCode: code, Header: http.Header{}, Body: body(``)}
+	})
+	s.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+		out := r.Data.(*mockDescribeOutput)
+		for _, state := range states[reqNum] {
+			out.Instances = append(out.Instances, &mockInstance{State: service.String(state)})
+		}
+	})
+	s.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+		reqNum++
+	})
+	s.Handlers.UnmarshalError.PushBack(func(r *request.Request) {
+		reqNum++
+		r.Error = awserr.New("MockServerError", "mock error", nil)
+	})
+
+	return s, &reqNum
+}
+
+func newWaiter(s *client.Client, acceptors []request.WaiterAcceptor) request.Waiter {
+	return request.Waiter{
+		Name:        "WaitUntilInstanceRunning",
+		MaxAttempts: 10,
+		Delay:       request.ConstantWaiterDelay(0),
+		Acceptors:   acceptors,
+		NewRequest: func(opts []request.Option) (*request.Request, error) {
+			return s.NewRequest(&request.Operation{Name: "DescribeInstances"}, nil, &mockDescribeOutput{}, opts...), nil
+		},
+	}
+}
+
+func TestWaiterPathAll(t *testing.T) {
+	s, reqNum := newWaiterClient([][]string{
+		{"pending", "pending"},
+		{"running", "pending"},
+		{"running", "running"},
+	}, nil)
+
+	w := newWaiter(s, []request.WaiterAcceptor{
+		{State: request.SuccessWaiterState, Matcher: request.PathAllWaiterMatch, Argument: "Instances[].State", Expected: "running"},
+	})
+
+	assert.NoError(t, w.WaitWithContext(context.Background()))
+	assert.Equal(t, 3, *reqNum)
+}
+
+func TestWaiterPathAnyFailure(t *testing.T) {
+	s, reqNum := newWaiterClient([][]string{
+		{"pending", "pending"},
+		{"terminated", "pending"},
+		{"running", "running"},
+	}, nil)
+
+	w := newWaiter(s, []request.WaiterAcceptor{
+		{State: request.SuccessWaiterState, Matcher: request.PathAllWaiterMatch, Argument: "Instances[].State", Expected: "running"},
+		{State: request.FailureWaiterState, Matcher: request.PathAnyWaiterMatch, Argument: "Instances[].State", Expected: "terminated"},
+	})
+
+	err := w.Wait()
+	assert.Error(t, err)
+	assert.Equal(t, request.WaiterResourceNotReadyErrCode, err.(awserr.Error).Code())
+	assert.Equal(t, 2, *reqNum)
+}
+
+func TestWaiterStatusAndErrorMatch(t *testing.T) {
+	s, reqNum := newWaiterClient([][]string{{}, {}, {}}, []int{500, 500, 200})
+
+	w := newWaiter(s, []request.WaiterAcceptor{
+		{State: request.SuccessWaiterState, Matcher: request.StatusWaiterMatch, Expected: 200},
+		{State: request.RetryWaiterState, Matcher: request.ErrorWaiterMatch, Expected: "MockServerError"},
+	})
+	w.ApplyOptions(request.WithWaiterRequestOptions(request.WithRequestRetryer(client.DefaultRetryer{})))
+
+	assert.NoError(t, w.Wait())
+	assert.Equal(t, 3, *reqNum)
+}
+
+func TestWaiterUnexpectedError(t *testing.T) {
+	s, reqNum := newWaiterClient([][]string{{}, {}}, []int{500})
+
+	w := newWaiter(s, []request.WaiterAcceptor{
+		{State: request.SuccessWaiterState, Matcher: request.StatusWaiterMatch, Expected: 200},
+	})
+	w.ApplyOptions(request.WithWaiterRequestOptions(request.WithRequestRetryer(client.DefaultRetryer{})))
+
+	err := w.Wait()
+	assert.Error(t, err)
+	assert.Equal(t, "MockServerError", err.(awserr.Error).Code())
+	assert.Equal(t, 1, *reqNum)
+}
+
+func TestWaiterMaxAttempts(t *testing.T) {
+	states := make([][]string, 10)
+	for i := range states {
+		states[i] = []string{"pending"}
+	}
+	s, reqNum := newWaiterClient(states, nil)
+
+	var delays []time.Duration
+	w := newWaiter(s, []request.WaiterAcceptor{
+		{State: request.SuccessWaiterState, Matcher: request.PathWaiterMatch, Argument: "Instances[0].State", Expected: "running"},
+	})
+	w.ApplyOptions(
+		request.WithWaiterMaxAttempts(3),
+		request.WithWaiterDelay(request.ConstantWaiterDelay(time.Second)),
+	)
+	w.SleepWithContext = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	err := w.Wait()
+	assert.Error(t, err)
+	assert.Equal(t, request.WaiterResourceNotReadyErrCode, err.(awserr.Error).Code())
+	assert.Equal(t, 3, *reqNum)
+	assert.Equal(t, []time.Duration{time.Second, time.Second}, delays)
+}
+
+func TestWaiterCanceledContext(t *testing.T) {
+	s, _ := newWaiterClient([][]string{{"pending"}, {"pending"}}, nil)
+
+	w := newWaiter(s, []request.WaiterAcceptor{
+		{State: request.SuccessWaiterState, Matcher: request.PathAllWaiterMatch, Argument: "Instances[].State", Expected: "running"},
+	})
+	w.Delay = request.ConstantWaiterDelay(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := w.WaitWithContext(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, request.CanceledErrCode, err.(awserr.Error).Code())
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golib/aws/service/awserr"
+)
+
+// A RetryMode selects the retry behavior of the default retryer service
+// clients use when Config.Retryer is not set.
+type RetryMode string
+
+const (
+	// RetryModeLegacy retries every retryable request up to MaxRetries times
+	// with exponential jittered backoff. This is the default retry mode.
+	RetryModeLegacy RetryMode = "legacy"
+
+	// RetryModeStandard retries like RetryModeLegacy, but each retry must
+	// be paid for from a retry quota shared by all requests of a client.
+	// The quota drains on failed attempts and refills on successful ones,
+	// so retries stop when a service is failing consistently instead of
+	// amplifying its load.
+	RetryModeStandard RetryMode = "standard"
+
+	// RetryModeAdaptive extends RetryModeStandard with a client side send
+	// rate limiter. The rate is reduced when the service responds with
+	// throttling errors, and slowly recovers on successful responses.
+	RetryModeAdaptive RetryMode = "adaptive"
+)
+
+// ParseRetryMode returns the RetryMode for the case insensitive name of the
+// mode. An error is returned if the name is not a known retry mode.
+func ParseRetryMode(v string) (RetryMode, error) {
+	switch mode := RetryMode(strings.ToLower(v)); mode {
+	case RetryModeLegacy, RetryModeStandard, RetryModeAdaptive:
+		return mode, nil
+	default:
+		return "", awserr.New("InvalidRetryMode",
+			fmt.Sprintf("unknown retry mode %q, expect one of %s, %s or %s",
+				v, RetryModeLegacy, RetryModeStandard, RetryModeAdaptive), nil)
+	}
+}
+
+// String returns the name of the retry mode.
+func (m RetryMode) String() string {
+	return string(m)
+}
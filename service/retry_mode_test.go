@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/golib/aws/service/awserr"
+)
+
+func TestParseRetryMode(t *testing.T) {
+	cases := map[string]RetryMode{
+		"legacy":   RetryModeLegacy,
+		"standard": RetryModeStandard,
+		"Adaptive": RetryModeAdaptive,
+	}
+
+	for v, expect := range cases {
+		mode, err := ParseRetryMode(v)
+		if err != nil {
+			t.Errorf("%s: expect no error, got %v", v, err)
+		}
+		if e, a := expect, mode; e != a {
+			t.Errorf("%s: expect %v mode, got %v", v, e, a)
+		}
+	}
+
+	_, err := ParseRetryMode("fast")
+	if err == nil {
+		t.Fatalf("expect error for unknown retry mode")
+	}
+	if e, a := "InvalidRetryMode", err.(awserr.Error).Code(); e != a {
+		t.Errorf("expect %v error code, got %v", e, a)
+	}
+}
+
+func TestMergeRetryMode(t *testing.T) {
+	cfg := NewConfig().WithRetryMode(RetryModeStandard)
+	cfg.MergeIn(&Config{})
+	if e, a := RetryModeStandard, *cfg.RetryMode; e != a {
+		t.Errorf("expect %v mode, got %v", e, a)
+	}
+
+	cfg.MergeIn(NewConfig().WithRetryMode(RetryModeAdaptive))
+	if e, a := RetryModeAdaptive, *cfg.RetryMode; e != a {
+		t.Errorf("expect %v mode, got %v", e, a)
+	}
+}
@@ -154,6 +154,13 @@ and signing requests.
 
 	region = us-east-1
 
+Retry mode selects the retry behavior of the default retryer, one of legacy,
+standard or adaptive. Max attempts is the number of times a request is made,
+including the first attempt, and is used if Config.MaxRetries is not set.
+
+	retry_mode = standard
+	max_attempts = 3
+
 Environment Variables
 
 When a Session is created several environment variables can be set to adjust
@@ -280,6 +280,18 @@ func mergeConfigSrcs(cfg, userCfg *service.Config, envCfg envConfig, sharedCfg s
 		}
 	}
 
+	// Retry mode and max attempts if not already set by user
+	if envCfg.EnableSharedConfig {
+		if cfg.RetryMode == nil && len(sharedCfg.RetryMode) > 0 {
+			cfg.WithRetryMode(sharedCfg.RetryMode)
+		}
+
+		maxRetries := service.IntValue(cfg.MaxRetries)
+		if (cfg.MaxRetries == nil || maxRetries == service.UseServiceDefaultRetries) && sharedCfg.MaxAttempts > 0 {
+			cfg.WithMaxRetries(sharedCfg.MaxAttempts - 1)
+		}
+	}
+
 	// Configure credentials if not already set
 	if cfg.Credentials == credentials.AnonymousCredentials && userCfg.Credentials == nil {
 		if len(envCfg.Creds.AccessKeyID) > 0 {
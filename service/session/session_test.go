@@ -128,6 +128,25 @@ func TestNewSessionWithOptions_OverrideSharedConfigEnable(t *testing.T) {
 	assert.Contains(t, creds.ProviderName, "SharedConfigCredentials")
 }
 
+func TestNewSessionWithOptions_SharedConfigRetryMode(t *testing.T) {
+	oldEnv := initSessionTestEnv()
+	defer popEnv(oldEnv)
+
+	os.Setenv("AWS_SDK_LOAD_CONFIG", "1")
+	os.Setenv("AWS_CONFIG_FILE", testConfigFilename)
+	os.Setenv("AWS_PROFILE", "retry_config")
+
+	s, err := NewSession()
+	assert.NoError(t, err)
+	assert.Equal(t, service.RetryModeAdaptive, *s.Config.RetryMode)
+	assert.Equal(t, 4, *s.Config.MaxRetries)
+
+	s, err = NewSession(service.NewConfig().WithRetryMode(service.RetryModeStandard).WithMaxRetries(1))
+	assert.NoError(t, err)
+	assert.Equal(t, service.RetryModeStandard, *s.Config.RetryMode)
+	assert.Equal(t, 1, *s.Config.MaxRetries)
+}
+
 func TestNewSessionWithOptions_OverrideSharedConfigDisable(t *testing.T) {
 	oldEnv := initSessionTestEnv()
 	defer popEnv(oldEnv)
@@ -3,8 +3,10 @@ package session
 import (
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/go-ini/ini"
+	"github.com/golib/aws/service"
 	"github.com/golib/aws/service/awserr"
 	"github.com/golib/aws/service/credentials"
 )
@@ -25,6 +27,10 @@ const (
 	// Additional Config fields
 	regionKey = `region`
 
+	// Retry Config fields
+	retryModeKey   = `retry_mode`   // optional
+	maxAttemptsKey = `max_attempts` // optional
+
 	// DefaultSharedConfigProfile is the default profile to be used when
 	// loading configuration from the config files if another profile name
 	// is not provided.
@@ -60,6 +66,18 @@ type sharedConfig struct {
 	//
 	//	region
 	Region string
+
+	// RetryMode is the retry mode of the default retryer used by service
+	// clients.
+	//
+	//	retry_mode
+	RetryMode service.RetryMode
+
+	// MaxAttempts is the maximum number of attempts a request is made,
+	// including the first attempt. Zero if not set.
+	//
+	//	max_attempts
+	MaxAttempts int
 }
 
 type sharedConfigFile struct {
@@ -209,6 +227,27 @@ func (cfg *sharedConfig) setFromIniFile(profile string, file sharedConfigFile) e
 		cfg.Region = v
 	}
 
+	// Retry Mode
+	if v := section.Key(retryModeKey).String(); len(v) > 0 {
+		mode, err := service.ParseRetryMode(v)
+		if err != nil {
+			return SharedConfigValueError{Profile: profile, Key: retryModeKey, Value: v, Err: err}
+		}
+		cfg.RetryMode = mode
+	}
+
+	// Max Attempts
+	if v := section.Key(maxAttemptsKey).String(); len(v) > 0 {
+		n, err := strconv.Atoi(v)
+		if err == nil && n < 1 {
+			err = fmt.Errorf("must be at least 1")
+		}
+		if err != nil {
+			return SharedConfigValueError{Profile: profile, Key: maxAttemptsKey, Value: v, Err: err}
+		}
+		cfg.MaxAttempts = n
+	}
+
 	return nil
 }
 
@@ -238,6 +277,35 @@ func (e SharedConfigLoadError) Error() string {
 	return awserr.SprintError(e.Code(), e.Message(), "", e.Err)
 }
 
+// SharedConfigValueError is an error for the shared config when a value of
+// the profile is not valid for its key.
+type SharedConfigValueError struct {
+	Profile string
+	Key     string
+	Value   string
+	Err     error
+}
+
+// Code is the short id of the error.
+func (e SharedConfigValueError) Code() string {
+	return "SharedConfigValueError"
+}
+
+// Message is the description of the error
+func (e SharedConfigValueError) Message() string {
+	return fmt.Sprintf("invalid value %q for %s in profile, %s", e.Value, e.Key, e.Profile)
+}
+
+// OrigErr is the underlying error that caused the failure.
+func (e SharedConfigValueError) OrigErr() error {
+	return e.Err
+}
+
+// Error satisfies the error interface.
+func (e SharedConfigValueError) Error() string {
+	return awserr.SprintError(e.Code(), e.Message(), "", e.Err)
+}
+
 // SharedConfigProfileNotExistsError is an error for the shared config when
 // the profile was not find in the config file.
 type SharedConfigProfileNotExistsError struct {
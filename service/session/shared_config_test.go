@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-ini/ini"
 	"github.com/golib/assert"
+	"github.com/golib/aws/service"
 	"github.com/golib/aws/service/credentials"
 )
 
@@ -119,6 +120,24 @@ func TestLoadSharedConfig(t *testing.T) {
 			},
 			Err: SharedConfigAssumeRoleError{RoleARN: "assume_role_wo_creds_role_arn"},
 		},
+		{
+			Filenames: []string{testConfigFilename},
+			Profile:   "retry_config",
+			Expected: sharedConfig{
+				RetryMode:   service.RetryModeAdaptive,
+				MaxAttempts: 5,
+			},
+		},
+		{
+			Filenames: []string{testConfigFilename},
+			Profile:   "invalid_retry_mode",
+			Err:       SharedConfigValueError{Profile: "invalid_retry_mode", Key: "retry_mode", Value: "fast"},
+		},
+		{
+			Filenames: []string{testConfigFilename},
+			Profile:   "invalid_max_attempts",
+			Err:       SharedConfigValueError{Profile: "invalid_max_attempts", Key: "max_attempts", Value: "0"},
+		},
 		{
 			Filenames: []string{filepath.Join("testdata", "shared_config_invalid_ini")},
 			Profile:   "profile_name",
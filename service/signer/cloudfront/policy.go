@@ -0,0 +1,218 @@
+// Package cloudfront implements signing of URLs and cookies for restricting
+// access to content served through a CloudFront style distribution.
+//
+// Signed URLs and cookies carry an RSA-SHA1 signature of a canned or custom
+// JSON policy, created with the private key of the distribution's trusted
+// key pair.
+package cloudfront
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/golib/aws/service/awserr"
+)
+
+// An AWSEpochTime wraps a time value providing JSON serialization needed for
+// policy dates which are represented as seconds since the unix epoch.
+type AWSEpochTime struct {
+	time.Time
+}
+
+// NewAWSEpochTime returns a new AWSEpochTime pointer wrapping the time value
+// provided.
+func NewAWSEpochTime(t time.Time) *AWSEpochTime {
+	return &AWSEpochTime{
+		Time: t,
+	}
+}
+
+// MarshalJSON serializes the epoch time as seconds since the unix epoch.
+func (t AWSEpochTime) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"AWS:EpochTime":%d}`, t.UTC().Unix())), nil
+}
+
+// An IPAddress wraps an IPAddress source IP providing JSON serialization
+// information.
+type IPAddress struct {
+	SourceIP string `json:"AWS:SourceIp"`
+}
+
+// A Condition defines the restrictions for how a signed URL or cookie can
+// be used.
+type Condition struct {
+	// Optional IP address mask the signed URL or cookie must be requested
+	// from, in CIDR notation, e.g. 192.0.2.0/24.
+	IPAddress *IPAddress `json:"IpAddress,omitempty"`
+
+	// Optional date the signed URL or cookie is valid after.
+	DateGreaterThan *AWSEpochTime `json:",omitempty"`
+
+	// Required date the signed URL or cookie is valid until.
+	DateLessThan *AWSEpochTime `json:",omitempty"`
+}
+
+// A Statement is a collection of conditions for resources.
+type Statement struct {
+	// The resource URL the URL or cookie will be signed for. May contain
+	// the * and ? wildcards to match multiple resources.
+	Resource string
+
+	// The set of conditions for this resource.
+	Condition Condition
+}
+
+// A Policy defines the resources a signed URL or cookie provides access to,
+// and the conditions it can be used under.
+type Policy struct {
+	// List of resource and condition statements.
+	// Signed URLs and cookies should only provide a single statement.
+	Statements []Statement `json:"Statement"`
+}
+
+// NewCannedPolicy returns a new Canned Policy constructed using the resource
+// and expires time. This can be used to generate the basic model for a Policy
+// that can be then augmented with additional conditions.
+func NewCannedPolicy(resource string, expires time.Time) *Policy {
+	return &Policy{
+		Statements: []Statement{
+			{
+				Resource: resource,
+				Condition: Condition{
+					DateLessThan: NewAWSEpochTime(expires),
+				},
+			},
+		},
+	}
+}
+
+// Validate verifies that the policy is valid and usable, and returns an
+// error if there is a problem.
+func (p *Policy) Validate() error {
+	if len(p.Statements) == 0 {
+		return awserr.New("InvalidPolicy", "at least one policy statement is required", nil)
+	}
+
+	for i, s := range p.Statements {
+		if s.Resource == "" {
+			return awserr.New("InvalidPolicy", fmt.Sprintf("statement at index %d does not have a resource", i), nil)
+		}
+
+		if s.Condition.DateLessThan == nil || s.Condition.DateLessThan.IsZero() {
+			return awserr.New("InvalidPolicy", fmt.Sprintf("statement at index %d does not have a valid DateLessThan condition", i), nil)
+		}
+
+		if s.Condition.DateGreaterThan != nil && !s.Condition.DateGreaterThan.Before(s.Condition.DateLessThan.Time) {
+			return awserr.New("InvalidPolicy", fmt.Sprintf("statement at index %d DateGreaterThan must be before DateLessThan", i), nil)
+		}
+	}
+
+	return nil
+}
+
+// isCanned returns true if the policy can be expressed as a canned policy,
+// which only restricts a single resource by its expiry time. CloudFront
+// rebuilds canned policies from the requested URL, so the resource must not
+// contain wildcards.
+func (p *Policy) isCanned() bool {
+	if len(p.Statements) != 1 {
+		return false
+	}
+
+	s := p.Statements[0]
+	return s.Condition.IPAddress == nil && s.Condition.DateGreaterThan == nil &&
+		!strings.Contains(s.Resource, "*")
+}
+
+// Sign will sign a policy using an RSA private key. It will return a base 64
+// encoded signature and policy if no error is encountered.
+//
+// The signature and policy should be added to the signed URL following the
+// guidelines in:
+// http://docs.aws.amazon.com/AmazonCloudFront/latest/DeveloperGuide/private-content-signed-urls.html
+func (p *Policy) Sign(privKey *rsa.PrivateKey) (b64Signature, b64Policy []byte, err error) {
+	if err = p.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	// Build and escape the policy
+	b64Policy, jsonPolicy, err := encodePolicy(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	awsEscapeEncoded(b64Policy)
+
+	// Build and escape the signature
+	b64Signature, err = signEncodedPolicy(rand.Reader, jsonPolicy, privKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	awsEscapeEncoded(b64Signature)
+
+	return b64Signature, b64Policy, nil
+}
+
+// signEncodedPolicy will sign and base 64 encode the JSON encoded policy.
+func signEncodedPolicy(randReader io.Reader, jsonPolicy []byte, privKey *rsa.PrivateKey) ([]byte, error) {
+	hash := sha1.New()
+	if _, err := bytes.NewReader(jsonPolicy).WriteTo(hash); err != nil {
+		return nil, awserr.New("SignPolicy", "failed to calculate signing hash", err)
+	}
+
+	sig, err := rsa.SignPKCS1v15(randReader, privKey, crypto.SHA1, hash.Sum(nil))
+	if err != nil {
+		return nil, awserr.New("SignPolicy", "failed to sign policy", err)
+	}
+
+	return encodeBase64(sig), nil
+}
+
+// encodePolicy encodes the Policy as JSON and also base 64 encodes it. HTML
+// characters such as & are not escaped, as CloudFront verifies the signature
+// of canned policies against the policy it rebuilds from the resource URL.
+func encodePolicy(p *Policy) (b64Policy, jsonPolicy []byte, err error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(p); err != nil {
+		return nil, nil, awserr.New("EncodePolicy", "failed to encode policy", err)
+	}
+
+	// Remove leading and trailing white space, JSON encoding will not include
+	// whitespace within the encoding.
+	jsonPolicy = bytes.TrimSpace(buf.Bytes())
+
+	return encodeBase64(jsonPolicy), jsonPolicy, nil
+}
+
+func encodeBase64(b []byte) []byte {
+	enc := make([]byte, base64.StdEncoding.EncodedLen(len(b)))
+	base64.StdEncoding.Encode(enc, b)
+
+	return enc
+}
+
+// awsEscapeEncoded will replace base64 encoding's special characters to be
+// URL safe.
+func awsEscapeEncoded(b []byte) {
+	for i, v := range b {
+		if r, ok := awsURLEscapes[v]; ok {
+			b[i] = r
+		}
+	}
+}
+
+var awsURLEscapes = map[byte]byte{
+	'+': '-',
+	'=': '_',
+	'/': '~',
+}
@@ -0,0 +1,116 @@
+package cloudfront
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func newTestPrivKey(t *testing.T) *rsa.PrivateKey {
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	return privKey
+}
+
+func TestEpochTimeMarshal(t *testing.T) {
+	v := NewAWSEpochTime(time.Unix(123456789, 0))
+	b, err := v.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"AWS:EpochTime":123456789}`, string(b))
+}
+
+func TestEncodeCannedPolicy(t *testing.T) {
+	p := NewCannedPolicy("https://example.com/a", time.Unix(1257894000, 0))
+
+	b64Policy, jsonPolicy, err := encodePolicy(p)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Statement":[{"Resource":"https://example.com/a","Condition":{"DateLessThan":{"AWS:EpochTime":1257894000}}}]}`, string(jsonPolicy))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(jsonPolicy), string(b64Policy))
+	assert.True(t, p.isCanned())
+
+	assert.False(t, NewCannedPolicy("https://example.com/*", time.Unix(1257894000, 0)).isCanned())
+}
+
+func TestEncodeCannedPolicyQueryString(t *testing.T) {
+	p := NewCannedPolicy("https://d.example/a?x=1&y=2<>", time.Unix(1257894000, 0))
+
+	_, jsonPolicy, err := encodePolicy(p)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Statement":[{"Resource":"https://d.example/a?x=1&y=2<>","Condition":{"DateLessThan":{"AWS:EpochTime":1257894000}}}]}`, string(jsonPolicy))
+}
+
+func TestEncodeCustomPolicy(t *testing.T) {
+	p := &Policy{
+		Statements: []Statement{
+			{
+				Resource: "https://example.com/*",
+				Condition: Condition{
+					IPAddress:       &IPAddress{SourceIP: "192.0.2.0/24"},
+					DateGreaterThan: NewAWSEpochTime(time.Unix(1257890000, 0)),
+					DateLessThan:    NewAWSEpochTime(time.Unix(1257894000, 0)),
+				},
+			},
+		},
+	}
+
+	_, jsonPolicy, err := encodePolicy(p)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Statement":[{"Resource":"https://example.com/*","Condition":{"IpAddress":{"AWS:SourceIp":"192.0.2.0/24"},"DateGreaterThan":{"AWS:EpochTime":1257890000},"DateLessThan":{"AWS:EpochTime":1257894000}}}]}`, string(jsonPolicy))
+	assert.False(t, p.isCanned())
+}
+
+func TestPolicyValidate(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		policy    *Policy
+		expectErr bool
+	}{
+		{NewCannedPolicy("https://example.com/a", now), false},
+		{&Policy{}, true},
+		{NewCannedPolicy("", now), true},
+		{NewCannedPolicy("https://example.com/a", time.Time{}), true},
+		{&Policy{Statements: []Statement{{
+			Resource: "https://example.com/a",
+			Condition: Condition{
+				DateGreaterThan: NewAWSEpochTime(now),
+				DateLessThan:    NewAWSEpochTime(now.Add(-time.Hour)),
+			},
+		}}}, true},
+	}
+
+	for i, c := range cases {
+		err := c.policy.Validate()
+		assert.Equal(t, c.expectErr, err != nil, "case %d, %v", i, err)
+	}
+}
+
+func TestAWSEscapeEncoded(t *testing.T) {
+	b := []byte("a+b=c/d")
+	awsEscapeEncoded(b)
+	assert.Equal(t, "a-b_c~d", string(b))
+}
+
+func TestPolicySign(t *testing.T) {
+	privKey := newTestPrivKey(t)
+	p := NewCannedPolicy("https://example.com/a", time.Now().Add(time.Hour))
+
+	b64Signature, b64Policy, err := p.Sign(privKey)
+	assert.NoError(t, err)
+
+	unescape := strings.NewReplacer("-", "+", "_", "=", "~", "/")
+	jsonPolicy, err := base64.StdEncoding.DecodeString(unescape.Replace(string(b64Policy)))
+	assert.NoError(t, err)
+	sig, err := base64.StdEncoding.DecodeString(unescape.Replace(string(b64Signature)))
+	assert.NoError(t, err)
+
+	hash := sha1.Sum(jsonPolicy)
+	assert.NoError(t, rsa.VerifyPKCS1v15(&privKey.PublicKey, crypto.SHA1, hash[:], sig))
+}
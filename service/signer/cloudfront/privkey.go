@@ -0,0 +1,57 @@
+package cloudfront
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/golib/aws/service/awserr"
+)
+
+// LoadPEMPrivKeyFile reads a PEM encoded RSA private key from the file name.
+// A new RSA private key will be returned if no error.
+func LoadPEMPrivKeyFile(name string) (*rsa.PrivateKey, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, awserr.New("LoadPrivKey", "failed to open private key file", err)
+	}
+	defer file.Close()
+
+	return LoadPEMPrivKey(file)
+}
+
+// LoadPEMPrivKey reads a PEM encoded RSA private key from the io.Reader.
+// A new RSA private key will be returned if no error.
+//
+// Both PKCS #1 ("RSA PRIVATE KEY") and PKCS #8 ("PRIVATE KEY") encoded keys
+// are supported.
+func LoadPEMPrivKey(reader io.Reader) (*rsa.PrivateKey, error) {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, awserr.New("LoadPrivKey", "failed to read private key", err)
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, awserr.New("LoadPrivKey", "no PEM encoded private key found", nil)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, awserr.New("LoadPrivKey", "failed to parse private key", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, awserr.New("LoadPrivKey", "private key is not an RSA private key", nil)
+	}
+
+	return rsaKey, nil
+}
@@ -0,0 +1,50 @@
+package cloudfront
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func TestLoadPKCS1PrivKey(t *testing.T) {
+	privKey := newTestPrivKey(t)
+
+	b := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privKey),
+	})
+
+	key, err := LoadPEMPrivKey(bytes.NewReader(b))
+	assert.NoError(t, err)
+	assert.Equal(t, privKey.D, key.D)
+}
+
+func TestLoadPKCS8PrivKeyFile(t *testing.T) {
+	privKey := newTestPrivKey(t)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privKey)
+	assert.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "cloudfront")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "private_key.pem")
+	err = ioutil.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	assert.NoError(t, err)
+
+	key, err := LoadPEMPrivKeyFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, privKey.D, key.D)
+}
+
+func TestLoadPrivKeyInvalid(t *testing.T) {
+	_, err := LoadPEMPrivKey(bytes.NewReader([]byte("not a pem file")))
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package cloudfront
+
+import (
+	"crypto/rsa"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// CookiePolicyName name of the policy cookie
+	CookiePolicyName = "CloudFront-Policy"
+
+	// CookieExpiresName name of the expires cookie
+	CookieExpiresName = "CloudFront-Expires"
+
+	// CookieSignatureName name of the signature cookie
+	CookieSignatureName = "CloudFront-Signature"
+
+	// CookieKeyIDName name of the signing Key ID cookie
+	CookieKeyIDName = "CloudFront-Key-Pair-Id"
+)
+
+// A CookieOptions optional additional options that can be applied to the
+// signed cookies.
+type CookieOptions struct {
+	Path   string
+	Domain string
+	Secure bool
+}
+
+// A CookieSigner provides signing utilities to sign Cookies for CloudFront
+// style resources. Using a private key and key pair ID the CookieSigner
+// creates signed cookies for either a canned or custom policy.
+//
+//	privKey, err := cloudfront.LoadPEMPrivKeyFile("private_key.pem")
+//	signer := cloudfront.NewCookieSigner("keyID", privKey, func(o *cloudfront.CookieOptions) {
+//	    o.Path = "/"
+//	    o.Domain = ".example.com"
+//	})
+//
+//	// Sign cookies to be valid for 1 hour from now.
+//	cookies, err := signer.Sign(rawURL, time.Now().Add(1*time.Hour))
+//	for _, c := range cookies {
+//	    http.SetCookie(w, c)
+//	}
+type CookieSigner struct {
+	keyID   string
+	privKey *rsa.PrivateKey
+
+	Opts CookieOptions
+}
+
+// NewCookieSigner constructs and returns a new CookieSigner to be used to for
+// signing cookies.
+func NewCookieSigner(keyID string, privKey *rsa.PrivateKey, opts ...func(*CookieOptions)) *CookieSigner {
+	signer := &CookieSigner{
+		keyID:   keyID,
+		privKey: privKey,
+	}
+
+	for _, opt := range opts {
+		opt(&signer.Opts)
+	}
+
+	return signer
+}
+
+// Sign returns the cookies needed to allow user agents to make arbitrary
+// requests to the resource until expires, using a canned policy. An error
+// is returned if the cookies cannot be signed.
+//
+// The three cookies returned are the CloudFront-Expires, CloudFront-Signature
+// and CloudFront-Key-Pair-Id cookies. If the URL contains wildcards it cannot
+// be signed with a canned policy, and CloudFront-Policy will be returned in
+// place of CloudFront-Expires.
+func (s CookieSigner) Sign(rawURL string, expires time.Time, opts ...func(*CookieOptions)) ([]*http.Cookie, error) {
+	p := NewCannedPolicy(rawURL, expires)
+	return s.sign(p, p.isCanned(), opts...)
+}
+
+// SignWithPolicy returns the cookies needed to allow user agents to make
+// arbitrary requests to the resources of the Policy provided. An error is
+// returned if the cookies cannot be signed.
+//
+// The three cookies returned are the CloudFront-Policy, CloudFront-Signature
+// and CloudFront-Key-Pair-Id cookies. Use Sign for cookies of a canned policy.
+func (s CookieSigner) SignWithPolicy(p *Policy, opts ...func(*CookieOptions)) ([]*http.Cookie, error) {
+	return s.sign(p, false, opts...)
+}
+
+// sign returns the cookies of the policy, with the CloudFront-Expires cookie
+// of a canned policy if canned is true.
+func (s CookieSigner) sign(p *Policy, canned bool, opts ...func(*CookieOptions)) ([]*http.Cookie, error) {
+	b64Signature, b64Policy, err := p.Sign(s.privKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var cookies []*http.Cookie
+	if canned {
+		expires := p.Statements[0].Condition.DateLessThan.UTC().Unix()
+		cookies = append(cookies, &http.Cookie{
+			Name:  CookieExpiresName,
+			Value: strconv.FormatInt(expires, 10),
+		})
+	} else {
+		cookies = append(cookies, &http.Cookie{
+			Name:  CookiePolicyName,
+			Value: string(b64Policy),
+		})
+	}
+
+	cookies = append(cookies,
+		&http.Cookie{
+			Name:  CookieSignatureName,
+			Value: string(b64Signature),
+		},
+		&http.Cookie{
+			Name:  CookieKeyIDName,
+			Value: s.keyID,
+		},
+	)
+
+	cookieOpts := s.Opts
+	for _, opt := range opts {
+		opt(&cookieOpts)
+	}
+
+	for _, c := range cookies {
+		c.Path = cookieOpts.Path
+		c.Domain = cookieOpts.Domain
+		c.Secure = cookieOpts.Secure
+	}
+
+	return cookies, nil
+}
@@ -0,0 +1,63 @@
+package cloudfront
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func TestCookieSignerCannedPolicy(t *testing.T) {
+	signer := NewCookieSigner("keyID", newTestPrivKey(t), func(o *CookieOptions) {
+		o.Path = "/"
+		o.Domain = ".example.com"
+	})
+
+	cookies, err := signer.Sign("https://example.com/a", time.Unix(1257894000, 0), func(o *CookieOptions) {
+		o.Secure = true
+	})
+	assert.NoError(t, err)
+	assert.Len(t, cookies, 3)
+
+	assert.Equal(t, CookieExpiresName, cookies[0].Name)
+	assert.Equal(t, "1257894000", cookies[0].Value)
+	assert.Equal(t, CookieSignatureName, cookies[1].Name)
+	assert.NotEmpty(t, cookies[1].Value)
+	assert.Equal(t, CookieKeyIDName, cookies[2].Name)
+	assert.Equal(t, "keyID", cookies[2].Value)
+
+	for _, c := range cookies {
+		assert.Equal(t, "/", c.Path)
+		assert.Equal(t, ".example.com", c.Domain)
+		assert.True(t, c.Secure)
+	}
+}
+
+func TestCookieSignerWildcardResource(t *testing.T) {
+	signer := NewCookieSigner("keyID", newTestPrivKey(t))
+
+	cookies, err := signer.Sign("https://example.com/*", time.Unix(1257894000, 0))
+	assert.NoError(t, err)
+	assert.Len(t, cookies, 3)
+	assert.Equal(t, CookiePolicyName, cookies[0].Name)
+
+	// A policy only restricting the expiry time is not canned.
+	cookies, err = signer.SignWithPolicy(NewCannedPolicy("https://example.com/a", time.Unix(1257894000, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, CookiePolicyName, cookies[0].Name)
+}
+
+func TestCookieSignerCustomPolicy(t *testing.T) {
+	signer := NewCookieSigner("keyID", newTestPrivKey(t))
+
+	p := NewCannedPolicy("https://example.com/*", time.Now().Add(time.Hour))
+	p.Statements[0].Condition.DateGreaterThan = NewAWSEpochTime(time.Now())
+
+	cookies, err := signer.SignWithPolicy(p)
+	assert.NoError(t, err)
+	assert.Len(t, cookies, 3)
+
+	assert.Equal(t, CookiePolicyName, cookies[0].Name)
+	assert.NotEmpty(t, cookies[0].Value)
+	assert.False(t, cookies[0].Secure)
+}
@@ -0,0 +1,75 @@
+package cloudfront
+
+import (
+	"crypto/rsa"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golib/aws/service/awserr"
+)
+
+// An URLSigner provides URL signing utilities to sign URLs for CloudFront
+// style resources. Using a private key and key pair ID the URLSigner creates
+// signed URLs for either a canned or custom policy.
+//
+//	privKey, err := cloudfront.LoadPEMPrivKeyFile("private_key.pem")
+//	signer := cloudfront.NewURLSigner("keyID", privKey)
+//
+//	// Sign URL to be valid for 1 hour from now.
+//	signedURL, err := signer.Sign(rawURL, time.Now().Add(1*time.Hour))
+type URLSigner struct {
+	keyID   string
+	privKey *rsa.PrivateKey
+}
+
+// NewURLSigner constructs and returns a new URLSigner to be used to for
+// signing URLs.
+func NewURLSigner(keyID string, privKey *rsa.PrivateKey) *URLSigner {
+	return &URLSigner{
+		keyID:   keyID,
+		privKey: privKey,
+	}
+}
+
+// Sign will sign a single URL to expire at the time of expires, using a
+// canned policy. An error is returned if the URL cannot be signed.
+func (s URLSigner) Sign(rawURL string, expires time.Time) (string, error) {
+	return s.SignWithPolicy(rawURL, NewCannedPolicy(rawURL, expires))
+}
+
+// SignWithPolicy will sign a URL with the Policy provided. If the policy only
+// restricts the expiry time of a resource which is the URL, the signed URL
+// will include the canned policy's Expires parameter, otherwise the full
+// custom policy will be included in the signed URL's Policy parameter.
+//
+// The policy's resource may contain wildcards to allow a single signature to
+// be shared across multiple URLs.
+func (s URLSigner) SignWithPolicy(rawURL string, p *Policy) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", awserr.New("InvalidURL", "failed to parse URL", err)
+	}
+
+	b64Signature, b64Policy, err := p.Sign(s.privKey)
+	if err != nil {
+		return "", err
+	}
+
+	var params string
+	if p.isCanned() && p.Statements[0].Resource == rawURL {
+		expires := p.Statements[0].Condition.DateLessThan.UTC().Unix()
+		params = "Expires=" + strconv.FormatInt(expires, 10)
+	} else {
+		params = "Policy=" + string(b64Policy)
+	}
+	params += "&Signature=" + string(b64Signature) + "&Key-Pair-Id=" + s.keyID
+
+	if u.RawQuery != "" {
+		u.RawQuery += "&" + params
+	} else {
+		u.RawQuery = params
+	}
+
+	return u.String(), nil
+}
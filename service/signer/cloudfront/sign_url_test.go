@@ -0,0 +1,75 @@
+package cloudfront
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func TestURLSignerCannedPolicy(t *testing.T) {
+	signer := NewURLSigner("keyID", newTestPrivKey(t))
+
+	signedURL, err := signer.Sign("https://example.com/a?b=c", time.Unix(1257894000, 0))
+	assert.NoError(t, err)
+
+	u, err := url.Parse(signedURL)
+	assert.NoError(t, err)
+
+	q := u.Query()
+	assert.Equal(t, "c", q.Get("b"))
+	assert.Equal(t, "1257894000", q.Get("Expires"))
+	assert.Equal(t, "keyID", q.Get("Key-Pair-Id"))
+	assert.NotEmpty(t, q.Get("Signature"))
+	assert.Empty(t, q.Get("Policy"))
+}
+
+func TestURLSignerCustomPolicy(t *testing.T) {
+	signer := NewURLSigner("keyID", newTestPrivKey(t))
+
+	p := NewCannedPolicy("https://example.com/*", time.Now().Add(time.Hour))
+	p.Statements[0].Condition.IPAddress = &IPAddress{SourceIP: "192.0.2.0/24"}
+
+	signedURL, err := signer.SignWithPolicy("https://example.com/a", p)
+	assert.NoError(t, err)
+
+	u, err := url.Parse(signedURL)
+	assert.NoError(t, err)
+
+	q := u.Query()
+	assert.Empty(t, q.Get("Expires"))
+	assert.NotEmpty(t, q.Get("Policy"))
+	assert.NotEmpty(t, q.Get("Signature"))
+	assert.Equal(t, "keyID", q.Get("Key-Pair-Id"))
+}
+
+func TestURLSignerWildcardResource(t *testing.T) {
+	signer := NewURLSigner("keyID", newTestPrivKey(t))
+
+	cases := map[string]string{
+		"wildcard":       "https://example.com/*",
+		"other resource": "https://example.com/b",
+	}
+
+	for name, resource := range cases {
+		p := NewCannedPolicy(resource, time.Unix(1257894000, 0))
+
+		signedURL, err := signer.SignWithPolicy("https://example.com/a", p)
+		assert.NoError(t, err, name)
+
+		u, err := url.Parse(signedURL)
+		assert.NoError(t, err, name)
+
+		q := u.Query()
+		assert.Empty(t, q.Get("Expires"), name)
+		assert.NotEmpty(t, q.Get("Policy"), name)
+	}
+}
+
+func TestURLSignerInvalidPolicy(t *testing.T) {
+	signer := NewURLSigner("keyID", newTestPrivKey(t))
+
+	_, err := signer.Sign("https://example.com/a", time.Time{})
+	assert.Error(t, err)
+}
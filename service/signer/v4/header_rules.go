@@ -36,6 +36,15 @@ func (m mapRule) IsValid(value string) bool {
 	return ok
 }
 
+// newHeaderMapRule returns a mapRule of the canonical form of the header keys.
+func newHeaderMapRule(keys []string) mapRule {
+	m := make(mapRule, len(keys))
+	for _, k := range keys {
+		m[http.CanonicalHeaderKey(k)] = struct{}{}
+	}
+	return m
+}
+
 // whitelist is a generic rule for whitelisting
 type whitelist struct {
 	rule
@@ -0,0 +1,56 @@
+package v4
+
+import (
+	"sync"
+
+	"github.com/golib/aws/service/credentials"
+)
+
+// maxDerivedKeys is the number of derived signing keys cached before the
+// cache is reset.
+const maxDerivedKeys = 64
+
+// derivedKeys caches the signing keys derived from credentials by their
+// credential scope, so the key derived for signing a request can be reused
+// by subsequent requests and event stream messages.
+var derivedKeys = struct {
+	sync.RWMutex
+	m map[string]derivedKey
+}{
+	m: map[string]derivedKey{},
+}
+
+type derivedKey struct {
+	secret string
+	key    []byte
+}
+
+// deriveSigningKey returns the signing key of the credentials for the
+// short formatted date, region and service.
+func deriveSigningKey(creds credentials.Value, shortTime, region, service string) []byte {
+	scope := creds.AccessKeyID + "/" + shortTime + "/" + region + "/" + service
+
+	derivedKeys.RLock()
+	v, ok := derivedKeys.m[scope]
+	derivedKeys.RUnlock()
+
+	// The secret is compared to prevent using a stale key when the
+	// credentials are rotated without changing the access key ID.
+	if ok && v.secret == creds.SecretAccessKey {
+		return v.key
+	}
+
+	date := makeHmac([]byte("AWS4"+creds.SecretAccessKey), []byte(shortTime))
+	regionKey := makeHmac(date, []byte(region))
+	serviceKey := makeHmac(regionKey, []byte(service))
+	key := makeHmac(serviceKey, []byte("aws4_request"))
+
+	derivedKeys.Lock()
+	if len(derivedKeys.m) >= maxDerivedKeys {
+		derivedKeys.m = map[string]derivedKey{}
+	}
+	derivedKeys.m[scope] = derivedKey{secret: creds.SecretAccessKey, key: key}
+	derivedKeys.Unlock()
+
+	return key
+}
@@ -0,0 +1,140 @@
+package v4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/credentials"
+	"github.com/golib/aws/service/eventstream"
+	"github.com/golib/aws/service/request"
+)
+
+const (
+	streamingEventsPayload = "AWS4-HMAC-SHA256-PAYLOAD"
+
+	// DateHeader is the event stream message header of the signing date.
+	DateHeader = ":date"
+
+	// ChunkSignatureHeader is the event stream message header of the
+	// message's chained signature.
+	ChunkSignatureHeader = ":chunk-signature"
+)
+
+// StreamSigner implements signing of event stream messages. Each message's
+// signature is chained to the signature of the previous message, starting
+// with the seed signature of the HTTP request which opened the stream.
+type StreamSigner struct {
+	region      string
+	service     string
+	credentials *credentials.Credentials
+
+	prevSig []byte
+}
+
+// NewStreamSigner returns a StreamSigner for the service and region, seeded
+// with the signature of the HTTP request which opened the stream.
+func NewStreamSigner(region, service string, seedSignature []byte, credentials *credentials.Credentials) *StreamSigner {
+	return &StreamSigner{
+		region:      region,
+		service:     service,
+		credentials: credentials,
+		prevSig:     seedSignature,
+	}
+}
+
+// NewStreamSignerFromRequest returns a StreamSigner seeded with the signature
+// of the SDK request, which must have already been signed with SignSDKRequest.
+func NewStreamSignerFromRequest(req *request.Request) (*StreamSigner, error) {
+	seedSignature, err := GetSignedRequestSignature(req.HTTPRequest.Header.Get("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+
+	name, region := sdkSigningScope(req)
+
+	return NewStreamSigner(region, name, seedSignature, req.Config.Credentials), nil
+}
+
+// GetSignedRequestSignature returns the signature from the Authorization
+// header value of a signed request.
+func GetSignedRequestSignature(auth string) ([]byte, error) {
+	const prefix = "Signature="
+
+	for _, part := range strings.Split(auth, ", ") {
+		if strings.HasPrefix(part, prefix) {
+			sig, err := hex.DecodeString(part[len(prefix):])
+			if err != nil {
+				return nil, awserr.New("InvalidSignature", "failed to decode request signature", err)
+			}
+			return sig, nil
+		}
+	}
+
+	return nil, awserr.New("MissingSignature", "request is not signed", nil)
+}
+
+// GetSignature signs the encoded headers and payload of an event stream
+// message at the date, returning the signature. The signature becomes the
+// seed of the next message's signature.
+func (s *StreamSigner) GetSignature(headers, payload []byte, date time.Time) ([]byte, error) {
+	credValues, err := s.credentials.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	shortTime := date.UTC().Format(shortTimeFormat)
+	credentialString := strings.Join([]string{
+		shortTime,
+		s.region,
+		s.service,
+		"aws4_request",
+	}, "/")
+
+	stringToSign := strings.Join([]string{
+		streamingEventsPayload,
+		date.UTC().Format(timeFormat),
+		credentialString,
+		hex.EncodeToString(s.prevSig),
+		hex.EncodeToString(makeSha256(headers)),
+		hex.EncodeToString(makeSha256(payload)),
+	}, "\n")
+
+	key := deriveSigningKey(credValues, shortTime, s.region, s.service)
+	signature := makeHmac(key, []byte(stringToSign))
+
+	s.prevSig = signature
+
+	return signature, nil
+}
+
+// SignMessage returns a signed event stream message wrapping the encoded
+// payload message. The signed message includes the :date and
+// :chunk-signature headers. An empty payload signs the end of the stream.
+func (s *StreamSigner) SignMessage(payload []byte, date time.Time) (eventstream.Message, error) {
+	// The date header value only carries millisecond precision, the signing
+	// date is truncated to seconds to match the signature's time format.
+	date = date.Truncate(time.Second)
+
+	headers := eventstream.Headers{}
+	headers.Set(DateHeader, eventstream.TimestampValue(date))
+
+	var encoded bytes.Buffer
+	if err := eventstream.EncodeHeaders(&encoded, headers); err != nil {
+		return eventstream.Message{}, err
+	}
+
+	signature, err := s.GetSignature(encoded.Bytes(), payload, date)
+	if err != nil {
+		return eventstream.Message{}, err
+	}
+
+	headers.Set(ChunkSignatureHeader, eventstream.BytesValue(signature))
+
+	return eventstream.Message{
+		Headers: headers,
+		Payload: payload,
+	}, nil
+}
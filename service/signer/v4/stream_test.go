@@ -0,0 +1,105 @@
+package v4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/credentials"
+	"github.com/golib/aws/service/eventstream"
+	"github.com/golib/aws/service/request"
+)
+
+func TestStreamSignerChainsSignatures(t *testing.T) {
+	creds := credentials.NewStaticCredentials("AKID", "SECRET", "SESSION")
+	seed := []byte("seed")
+	date := time.Unix(1369353600, 0)
+
+	signer := NewStreamSigner("us-east-1", "service", seed, creds)
+
+	sig1, err := signer.GetSignature([]byte("headers"), []byte("payload"), date)
+	assert.NoError(t, err)
+
+	sig2, err := signer.GetSignature([]byte("headers"), []byte("payload"), date)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sig1, sig2, "expect signatures to be chained")
+
+	credValues, _ := creds.Get()
+	key := deriveSigningKey(credValues, "20130524", "us-east-1", "service")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256-PAYLOAD",
+		"20130524T000000Z",
+		"20130524/us-east-1/service/aws4_request",
+		hex.EncodeToString(sig1),
+		hex.EncodeToString(makeSha256([]byte("headers"))),
+		hex.EncodeToString(makeSha256([]byte("payload"))),
+	}, "\n")
+	assert.Equal(t, makeHmac(key, []byte(stringToSign)), sig2)
+}
+
+func TestStreamSignerFromRequest(t *testing.T) {
+	svc := awstesting.NewClient(&service.Config{
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", "SESSION"),
+		Region:      service.String("us-west-2"),
+	})
+	r := svc.NewRequest(
+		&request.Operation{
+			Name:       "StartStream",
+			HTTPMethod: "POST",
+			HTTPPath:   "/",
+		},
+		nil,
+		nil,
+	)
+
+	_, err := NewStreamSignerFromRequest(r)
+	assert.Error(t, err, "expect error for unsigned request")
+
+	SignSDKRequest(r)
+	assert.NoError(t, r.Error)
+
+	signer, err := NewStreamSignerFromRequest(r)
+	assert.NoError(t, err)
+
+	seed, err := GetSignedRequestSignature(r.HTTPRequest.Header.Get("Authorization"))
+	assert.NoError(t, err)
+	assert.Equal(t, seed, signer.prevSig)
+	assert.Equal(t, "us-west-2", signer.region)
+}
+
+func TestStreamSignerSignMessage(t *testing.T) {
+	creds := credentials.NewStaticCredentials("AKID", "SECRET", "SESSION")
+	signer := NewStreamSigner("us-east-1", "service", []byte("seed"), creds)
+
+	var payload bytes.Buffer
+	err := eventstream.NewEncoder(&payload).Encode(eventstream.Message{
+		Headers: eventstream.Headers{{Name: ":event-type", Value: eventstream.StringValue("AudioEvent")}},
+		Payload: []byte("audio"),
+	})
+	assert.NoError(t, err)
+
+	msg, err := signer.SignMessage(payload.Bytes(), time.Unix(1369353600, 500))
+	assert.NoError(t, err)
+
+	date := msg.Headers.Get(DateHeader)
+	if assert.NotNil(t, date) {
+		assert.Equal(t, time.Unix(1369353600, 0), date.Get())
+	}
+	sig := msg.Headers.Get(ChunkSignatureHeader)
+	if assert.NotNil(t, sig) {
+		assert.Equal(t, signer.prevSig, sig.Get())
+	}
+
+	var framed bytes.Buffer
+	assert.NoError(t, eventstream.NewEncoder(&framed).Encode(msg))
+
+	decoded, err := eventstream.NewDecoder(&framed).Decode(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, payload.Bytes(), decoded.Payload)
+}
@@ -9,7 +9,6 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
-	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -21,6 +20,7 @@ import (
 
 	"github.com/golib/aws/service"
 	"github.com/golib/aws/service/awsutil"
+	"github.com/golib/aws/service/client/metadata"
 	"github.com/golib/aws/service/credentials"
 	"github.com/golib/aws/service/request"
 )
@@ -39,6 +39,7 @@ var ignoredHeaders = rules{
 		mapRule{
 			"Authorization": struct{}{},
 			"User-Agent":    struct{}{},
+			"Expect":        struct{}{},
 		},
 	},
 }
@@ -114,12 +115,52 @@ type Signer struct {
 	// is nil, nothing will be logged.
 	Logger service.Logger
 
+	// The structured logger signing information will be written to. If set
+	// it is used instead of Logger.
+	StructuredLogger service.StructuredLogger
+
+	// The redactor masking sensitive headers and query parameters in the
+	// logged signing information. Defaults to the redactor of an empty
+	// service.Config, masking service.DefaultLogRedactedHeaders and
+	// service.DefaultLogRedactedQuery.
+	LogRedactor *service.LogRedactor
+
 	// Disables the Signer's moving HTTP header key/value pairs from the HTTP
 	// request header to the request's query string. This is most commonly used
 	// with pre-signed requests preventing headers from being added to the
 	// request's query string.
 	DisableHeaderHoisting bool
 
+	// Disables the escaping of the request's URI path when building the
+	// canonical string of the signature. Use this for services which do not
+	// need the additional escaping, such as S3.
+	DisableURIPathEscaping bool
+
+	// Adds the request body's digest to the request as the X-Aws-Content-Sha256
+	// header. Required by services which validate the header, such as S3
+	// and Glacier.
+	IncludeContentSHA256Header bool
+
+	// Signs the request with the UNSIGNED-PAYLOAD body digest instead of
+	// computing the SHA256 of the request body.
+	UnsignedPayload bool
+
+	// Additional headers which must be signed as HTTP headers. These headers
+	// will not be hoisted into the query string of presigned requests.
+	SignedHeaders []string
+
+	// Additional headers which will not be included in the signature.
+	//
+	// If none of DisableURIPathEscaping, IncludeContentSHA256Header,
+	// UnsignedPayload, SignedHeaders and IgnoredHeaders are set, the
+	// default signer options of the service are used, see
+	// metadata.DefaultSignerOptions.
+	IgnoredHeaders []string
+
+	// optionsSet is true if the signer options were set from the SDK
+	// request's ClientInfo, and are used as is.
+	optionsSet bool
+
 	// currentTimeFn returns the time value which represents the current time.
 	// This value should only be used for testing. If it is nil the default
 	// time.Now will be used.
@@ -151,10 +192,14 @@ type signingCtx struct {
 	ExpireTime       time.Duration
 	SignedHeaderVals http.Header
 
-	credValues         credentials.Value
-	isPresign          bool
-	formattedTime      string
-	formattedShortTime string
+	DisableURIPathEscaping bool
+
+	credValues          credentials.Value
+	isPresign           bool
+	unsignedPayload     bool
+	includeSHA256Header bool
+	formattedTime       string
+	formattedShortTime  string
 
 	bodyDigest       string
 	signedHeaders    string
@@ -235,6 +280,8 @@ func (v4 Signer) signWithBody(r *http.Request, body io.ReadSeeker, serviceName,
 		currentTimeFn = time.Now
 	}
 
+	opts := v4.signerOptions(serviceName)
+
 	ctx := &signingCtx{
 		Request:     r,
 		Body:        body,
@@ -244,6 +291,10 @@ func (v4 Signer) signWithBody(r *http.Request, body io.ReadSeeker, serviceName,
 		isPresign:   exp != 0,
 		ServiceName: serviceName,
 		Region:      region,
+
+		DisableURIPathEscaping: opts.DisableURIPathEscaping,
+		unsignedPayload:        opts.UnsignedPayload,
+		includeSHA256Header:    opts.IncludeContentSHA256Header,
 	}
 
 	if ctx.isRequestSigned() {
@@ -263,7 +314,7 @@ func (v4 Signer) signWithBody(r *http.Request, body io.ReadSeeker, serviceName,
 	}
 
 	ctx.assignAmzQueryValues()
-	ctx.build(v4.DisableHeaderHoisting)
+	ctx.build(v4.DisableHeaderHoisting, v4.ignoredHeaderRules(), v4.queryHoistingRules())
 
 	// If the request is not presigned the body should be attached to it. This
 	// prevents the confusion of wanting to send a signed request without
@@ -286,6 +337,50 @@ func (v4 Signer) signWithBody(r *http.Request, body io.ReadSeeker, serviceName,
 	return ctx.SignedHeaderVals, nil
 }
 
+// signerOptions returns the options the service's requests are signed with.
+// A Signer not created for an SDK request without any options set uses the
+// default options of the service.
+func (v4 Signer) signerOptions(serviceName string) metadata.SignerOptions {
+	opts := metadata.SignerOptions{
+		DisableURIPathEscaping:     v4.DisableURIPathEscaping,
+		IncludeContentSHA256Header: v4.IncludeContentSHA256Header,
+		UnsignedPayload:            v4.UnsignedPayload,
+		SignedHeaders:              v4.SignedHeaders,
+		IgnoredHeaders:             v4.IgnoredHeaders,
+	}
+	if !v4.optionsSet && opts.IsZero() {
+		return metadata.DefaultSignerOptions(serviceName)
+	}
+
+	return opts
+}
+
+// ignoredHeaderRules returns the rules for headers which may be signed,
+// excluding the signer's additional ignored headers.
+func (v4 Signer) ignoredHeaderRules() rule {
+	if len(v4.IgnoredHeaders) == 0 {
+		return ignoredHeaders
+	}
+
+	return inclusiveRules{
+		ignoredHeaders,
+		blacklist{newHeaderMapRule(v4.IgnoredHeaders)},
+	}
+}
+
+// queryHoistingRules returns the rules for headers which may be hoisted into
+// the query string, excluding the signer's additional signed headers.
+func (v4 Signer) queryHoistingRules() rule {
+	if len(v4.SignedHeaders) == 0 {
+		return allowedQueryHoisting
+	}
+
+	return inclusiveRules{
+		allowedQueryHoisting,
+		blacklist{newHeaderMapRule(v4.SignedHeaders)},
+	}
+}
+
 func (ctx *signingCtx) handlePresignRemoval() {
 	if !ctx.isPresign {
 		return
@@ -345,27 +440,36 @@ func signSDKRequestWithCurrTime(req *request.Request, curTimeFn func() time.Time
 		return
 	}
 
-	region := req.ClientInfo.SigningRegion
-	if region == "" {
-		region = service.StringValue(req.Config.Region)
-	}
+	name, region := sdkSigningScope(req)
 
-	name := req.ClientInfo.SigningName
-	if name == "" {
-		name = req.ClientInfo.ServiceName
-	}
+	// Correct the signing time with the offset between the service's clock
+	// and the local clock, if one is known for the endpoint.
+	skew := request.ClockSkew(req.ClientInfo.Endpoint)
+
+	opts := req.ClientInfo.SignerOptions
 
 	v4 := NewSigner(req.Config.Credentials, func(v4 *Signer) {
 		v4.Debug = req.Config.LogLevel.Value()
 		v4.Logger = req.Config.Logger
-		v4.DisableHeaderHoisting = req.NotHoist
-		v4.currentTimeFn = curTimeFn
+		v4.StructuredLogger = req.Config.StructuredLogger
+		v4.LogRedactor = req.Config.LogRedactor()
+		v4.DisableHeaderHoisting = req.NotHoist || opts.DisableHeaderHoisting
+		v4.DisableURIPathEscaping = opts.DisableURIPathEscaping
+		v4.IncludeContentSHA256Header = opts.IncludeContentSHA256Header
+		v4.UnsignedPayload = opts.UnsignedPayload || service.BoolValue(req.Config.DisableBodyDigest)
+		v4.SignedHeaders = opts.SignedHeaders
+		v4.IgnoredHeaders = opts.IgnoredHeaders
+		v4.optionsSet = true
+		v4.currentTimeFn = func() time.Time {
+			return curTimeFn().Add(skew)
+		}
 	})
 
 	signingTime := req.Time
 	if !req.LastSignedAt.IsZero() {
 		signingTime = req.LastSignedAt
 	}
+	signingTime = signingTime.Add(skew)
 
 	signedHeaders, err := v4.signWithBody(req.HTTPRequest, req.Body, name, region, req.ExpireTime, signingTime)
 	if err != nil {
@@ -374,30 +478,99 @@ func signSDKRequestWithCurrTime(req *request.Request, curTimeFn func() time.Time
 		return
 	}
 
+	// The signer sets the request's body as the HTTP request's body. Reset
+	// it to a body which is safe to retry while a previous attempt may still
+	// be reading it, e.g. after an early response to Expect: 100-continue.
+	if req.ExpireTime == 0 {
+		req.ResetBody()
+	}
+
 	req.SignedHeaderVals = signedHeaders
 	req.LastSignedAt = curTimeFn()
+
+	// The credentials were just retrieved, or checked not to be expired, by
+	// the signer, so they are not refreshed to read their provider's name.
+	if creds, err := v4.Credentials.Get(); err == nil {
+		req.CredentialProvider = creds.ProviderName
+	}
 }
 
-const logSignInfoMsg = `DEBUG: Request Signature:
----[ CANONICAL STRING  ]-----------------------------
-%s
----[ STRING TO SIGN ]--------------------------------
-%s%s
------------------------------------------------------`
-const logSignedURLMsg = `
----[ SIGNED URL ]------------------------------------
-%s`
+// sdkSigningScope returns the service signing name and region of the SDK
+// request.
+func sdkSigningScope(req *request.Request) (name, region string) {
+	region = req.ClientInfo.SigningRegion
+	if region == "" {
+		region = service.StringValue(req.Config.Region)
+	}
+
+	name = req.ClientInfo.SigningName
+	if name == "" {
+		name = req.ClientInfo.ServiceName
+	}
+
+	return name, region
+}
 
 func (v4 *Signer) logSigningInfo(ctx *signingCtx) {
-	signedURLMsg := ""
+	logger := v4.StructuredLogger
+	if logger == nil {
+		if v4.Logger == nil {
+			return
+		}
+		logger = service.NewStructuredLogger(v4.Logger)
+	}
+
+	redactor := v4.LogRedactor
+	if redactor == nil {
+		redactor = (&service.Config{}).LogRedactor()
+	}
+
+	fields := []interface{}{
+		"service", ctx.ServiceName,
+		"region", ctx.Region,
+		"canonical_string", redactCanonicalString(redactor, ctx.canonicalString),
+		"string_to_sign", ctx.stringToSign,
+	}
 	if ctx.isPresign {
-		signedURLMsg = fmt.Sprintf(logSignedURLMsg, ctx.Request.URL.String())
+		fields = append(fields, "signed_url", redactor.URL(ctx.Request.URL).String())
+	}
+
+	logger.LogFields(service.SeverityDebug, "Request signature", fields...)
+}
+
+// redactCanonicalString returns the canonical string with the values of the
+// headers and query parameters masked by the redactor replaced.
+//
+// The canonical string is the method, path, query, the canonical headers, a
+// blank line, the signed headers and the body digest, separated by newlines.
+func redactCanonicalString(redactor *service.LogRedactor, canonicalString string) string {
+	lines := strings.Split(canonicalString, "\n")
+	if len(lines) < 3 {
+		return canonicalString
+	}
+
+	if lines[2] != "" {
+		params := strings.Split(lines[2], "&")
+		for i, param := range params {
+			kv := strings.SplitN(param, "=", 2)
+			if k, err := url.QueryUnescape(kv[0]); err == nil && redactor.IsRedactedQuery(k) {
+				params[i] = kv[0] + "=" + service.LogRedactedValue
+			}
+		}
+		lines[2] = strings.Join(params, "&")
+	}
+
+	for i := 3; i < len(lines) && lines[i] != ""; i++ {
+		kv := strings.SplitN(lines[i], ":", 2)
+		if len(kv) == 2 && redactor.IsRedactedHeader(kv[0]) {
+			lines[i] = kv[0] + ":" + service.LogRedactedValue
+		}
 	}
-	msg := fmt.Sprintf(logSignInfoMsg, ctx.canonicalString, ctx.stringToSign, signedURLMsg)
-	v4.Logger.Log(msg)
+
+	return strings.Join(lines, "\n")
 }
 
-func (ctx *signingCtx) build(disableHeaderHoisting bool) {
+func (ctx *signingCtx) build(disableHeaderHoisting bool, ignoredRule, hoistingRule rule) {
 	ctx.buildTime()             // no depends
 	ctx.buildCredentialString() // no depends
 
@@ -405,7 +578,7 @@ func (ctx *signingCtx) build(disableHeaderHoisting bool) {
 	if ctx.isPresign {
 		if !disableHeaderHoisting {
 			urlValues := url.Values{}
-			urlValues, unsignedHeaders = buildQuery(allowedQueryHoisting, unsignedHeaders) // no depends
+			urlValues, unsignedHeaders = buildQuery(hoistingRule, unsignedHeaders) // no depends
 			for k := range urlValues {
 				ctx.Query[k] = urlValues[k]
 			}
@@ -413,7 +586,7 @@ func (ctx *signingCtx) build(disableHeaderHoisting bool) {
 	}
 
 	ctx.buildBodyDigest()
-	ctx.buildCanonicalHeaders(ignoredHeaders, unsignedHeaders)
+	ctx.buildCanonicalHeaders(ignoredRule, unsignedHeaders)
 	ctx.buildCanonicalString() // depends on canon headers / signed headers
 	ctx.buildStringToSign()    // depends on canon string
 	ctx.buildSignature()       // depends on string to sign
@@ -524,7 +697,7 @@ func (ctx *signingCtx) buildCanonicalString() {
 		uri = "/"
 	}
 
-	if ctx.ServiceName != "s3" {
+	if !ctx.DisableURIPathEscaping {
 		uri = awsutil.EscapePath(uri, false)
 	}
 
@@ -548,19 +721,18 @@ func (ctx *signingCtx) buildStringToSign() {
 }
 
 func (ctx *signingCtx) buildSignature() {
-	secret := ctx.credValues.SecretAccessKey
-	date := makeHmac([]byte("AWS4"+secret), []byte(ctx.formattedShortTime))
-	region := makeHmac(date, []byte(ctx.Region))
-	service := makeHmac(region, []byte(ctx.ServiceName))
-	credentials := makeHmac(service, []byte("aws4_request"))
-	signature := makeHmac(credentials, []byte(ctx.stringToSign))
+	key := deriveSigningKey(ctx.credValues, ctx.formattedShortTime, ctx.Region, ctx.ServiceName)
+	signature := makeHmac(key, []byte(ctx.stringToSign))
 	ctx.signature = hex.EncodeToString(signature)
 }
 
 func (ctx *signingCtx) buildBodyDigest() {
 	hash := ctx.Request.Header.Get("X-Aws-Content-Sha256")
 	if hash == "" {
-		if ctx.isPresign {
+		if ctx.isPresign || ctx.unsignedPayload {
+			hash = "UNSIGNED-PAYLOAD"
+		} else if ctx.Body != nil && !service.IsReaderSeekable(ctx.Body) {
+			// Reading the body would consume it, it cannot be signed.
 			hash = "UNSIGNED-PAYLOAD"
 		} else if ctx.Body == nil {
 			hash = emptyStringSHA256
@@ -568,8 +740,7 @@ func (ctx *signingCtx) buildBodyDigest() {
 			hash = hex.EncodeToString(makeSha256Reader(ctx.Body))
 		}
 
-		switch ctx.ServiceName {
-		case "s3", "glacier", "mock":
+		if ctx.includeSHA256Header {
 			ctx.Request.Header.Set("X-Aws-Content-Sha256", hash)
 		}
 	}
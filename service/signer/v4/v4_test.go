@@ -14,6 +14,8 @@ import (
 
 	"github.com/golib/aws/service"
 	"github.com/golib/aws/service/awstesting"
+	"github.com/golib/aws/service/client"
+	"github.com/golib/aws/service/client/metadata"
 	"github.com/golib/aws/service/credentials"
 	"github.com/golib/aws/service/request"
 )
@@ -138,6 +140,92 @@ func TestPresignEmptyBodyS3(t *testing.T) {
 	assert.Equal(t, "UNSIGNED-PAYLOAD", hash)
 }
 
+func TestSignBodyWithoutContentSHA256Header(t *testing.T) {
+	req, body := buildRequest("service", "us-east-1", "hello")
+	signer := buildSigner()
+	signer.Sign(req, body, "service", "us-east-1", time.Now())
+	assert.Empty(t, req.Header.Get("X-Aws-Content-Sha256"))
+
+	req, body = buildRequest("service", "us-east-1", "hello")
+	signer.IncludeContentSHA256Header = true
+	signer.Sign(req, body, "service", "us-east-1", time.Now())
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", req.Header.Get("X-Aws-Content-Sha256"))
+}
+
+func TestSignS3DefaultSignerOptions(t *testing.T) {
+	req, body := buildRequest("s3", "us-east-1", "hello")
+	signer := buildSigner()
+	signer.Sign(req, body, "s3", "us-east-1", time.Unix(0, 0))
+	defaultSig := req.Header.Get("Authorization")
+
+	req, body = buildRequest("s3", "us-east-1", "hello")
+	signer.DisableURIPathEscaping = true
+	signer.IncludeContentSHA256Header = true
+	signer.Sign(req, body, "s3", "us-east-1", time.Unix(0, 0))
+	assert.Equal(t, defaultSig, req.Header.Get("Authorization"))
+
+	// Options set on the signer replace the service's defaults.
+	req, body = buildRequest("s3", "us-east-1", "hello")
+	signer.IncludeContentSHA256Header = false
+	signer.Sign(req, body, "s3", "us-east-1", time.Unix(0, 0))
+	assert.Empty(t, req.Header.Get("X-Aws-Content-Sha256"))
+}
+
+func TestSignUnsignedPayload(t *testing.T) {
+	req, body := buildRequest("service", "us-east-1", "hello")
+	signer := buildSigner()
+	signer.UnsignedPayload = true
+	signer.IncludeContentSHA256Header = true
+	signer.Sign(req, body, "service", "us-east-1", time.Now())
+	assert.Equal(t, "UNSIGNED-PAYLOAD", req.Header.Get("X-Aws-Content-Sha256"))
+}
+
+func TestSignNonSeekableBody(t *testing.T) {
+	req, _ := buildRequest("service", "us-east-1", "")
+	body := service.ReadSeekCloser(ioutil.NopCloser(strings.NewReader("hello")))
+	signer := buildSigner()
+	signer.IncludeContentSHA256Header = true
+	signer.Sign(req, body, "service", "us-east-1", time.Now())
+	assert.Equal(t, "UNSIGNED-PAYLOAD", req.Header.Get("X-Aws-Content-Sha256"))
+
+	b, err := ioutil.ReadAll(req.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(b), "expect body not to be consumed by signing")
+}
+
+func TestSignURIPathEscaping(t *testing.T) {
+	req, body := buildRequest("service", "us-east-1", "{}")
+	signer := buildSigner()
+	signer.Sign(req, body, "service", "us-east-1", time.Unix(0, 0))
+	escapedSig := req.Header.Get("Authorization")
+
+	req, body = buildRequest("service", "us-east-1", "{}")
+	signer.DisableURIPathEscaping = true
+	signer.Sign(req, body, "service", "us-east-1", time.Unix(0, 0))
+	assert.NotEqual(t, escapedSig, req.Header.Get("Authorization"))
+}
+
+func TestSignIgnoredHeaders(t *testing.T) {
+	req, body := buildRequest("dynamodb", "us-east-1", "{}")
+	signer := buildSigner()
+	signer.IgnoredHeaders = []string{"x-aws-target", "X-Aws-Meta-Other-Header"}
+	signer.Sign(req, body, "dynamodb", "us-east-1", time.Unix(0, 0))
+
+	auth := req.Header.Get("Authorization")
+	assert.Contains(t, auth, "SignedHeaders=content-length;content-type;host;x-aws-date;x-aws-meta-other-header_with_underscore;x-aws-security-token,")
+}
+
+func TestPresignSignedHeaders(t *testing.T) {
+	req, body := buildRequest("dynamodb", "us-east-1", "{}")
+	signer := buildSigner()
+	signer.SignedHeaders = []string{"X-Aws-Target"}
+	signer.Presign(req, body, "dynamodb", "us-east-1", 300*time.Second, time.Unix(0, 0))
+
+	q := req.URL.Query()
+	assert.Empty(t, q.Get("X-Aws-Target"))
+	assert.Contains(t, q.Get("X-Aws-SignedHeaders"), "x-aws-target")
+}
+
 func TestSignPrecomputedBodyChecksum(t *testing.T) {
 	req, body := buildRequest("dynamodb", "us-east-1", "hello")
 	req.Header.Set("X-Aws-Content-Sha256", "PRECOMPUTED")
@@ -171,6 +259,63 @@ func TestAnonymousCredentials(t *testing.T) {
 	assert.Empty(t, hQ.Get("X-Aws-Date"))
 }
 
+func TestSignSDKRequestSignerOptions(t *testing.T) {
+	newS3Client := func(opts metadata.SignerOptions) *client.Client {
+		return client.New(service.Config{
+			Credentials: credentials.NewStaticCredentials("AKID", "SECRET", "SESSION"),
+			Region:      service.String("us-west-2"),
+		}, metadata.ClientInfo{ServiceName: "s3", Endpoint: "http://endpoint", SignerOptions: opts}, request.Handlers{})
+	}
+
+	// The service's default signer options are used if none are set.
+	svc := newS3Client(metadata.SignerOptions{})
+	assert.Equal(t, metadata.DefaultSignerOptions("s3"), svc.ClientInfo.SignerOptions)
+
+	r := svc.NewRequest(&request.Operation{Name: "PutObject", HTTPMethod: "PUT", HTTPPath: "/"}, nil, nil)
+	r.SetStringBody("hello")
+	SignSDKRequest(r)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", r.HTTPRequest.Header.Get("X-Aws-Content-Sha256"))
+
+	// Options set by the client are used as is.
+	svc = newS3Client(metadata.SignerOptions{SignedHeaders: []string{"X-Aws-Meta-Key"}})
+
+	r = svc.NewRequest(&request.Operation{Name: "PutObject", HTTPMethod: "PUT", HTTPPath: "/"}, nil, nil)
+	r.SetStringBody("hello")
+	SignSDKRequest(r)
+	assert.Empty(t, r.HTTPRequest.Header.Get("X-Aws-Content-Sha256"))
+	assert.NotEmpty(t, r.HTTPRequest.Header.Get("Authorization"))
+}
+
+func TestSignSDKRequestDisableBodyDigest(t *testing.T) {
+	svc := awstesting.NewClient(&service.Config{
+		Credentials:       credentials.NewStaticCredentials("AKID", "SECRET", "SESSION"),
+		Region:            service.String("us-west-2"),
+		DisableBodyDigest: service.Bool(true),
+	})
+	svc.ClientInfo.SignerOptions.IncludeContentSHA256Header = true
+
+	r := svc.NewRequest(&request.Operation{Name: "PutItem", HTTPMethod: "POST", HTTPPath: "/"}, nil, nil)
+	r.SetStringBody("hello")
+	SignSDKRequest(r)
+
+	assert.Equal(t, "UNSIGNED-PAYLOAD", r.HTTPRequest.Header.Get("X-Aws-Content-Sha256"))
+	assert.NotEmpty(t, r.HTTPRequest.Header.Get("Authorization"))
+}
+
+func TestSignSDKRequestCredentialProvider(t *testing.T) {
+	svc := awstesting.NewClient(&service.Config{
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", "SESSION"),
+		Region:      service.String("us-west-2"),
+	})
+
+	r := svc.NewRequest(&request.Operation{Name: "PutItem", HTTPMethod: "POST", HTTPPath: "/"}, nil, nil)
+	assert.Empty(t, r.CredentialProvider)
+
+	SignSDKRequest(r)
+	assert.Nil(t, r.Error)
+	assert.Equal(t, credentials.StaticProviderName, r.CredentialProvider)
+}
+
 func TestIgnoreResignRequestWithValidCreds(t *testing.T) {
 	svc := awstesting.NewClient(&service.Config{
 		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", "SESSION"),
@@ -326,6 +471,30 @@ func TestResignRequestExpiredRequest(t *testing.T) {
 	assert.NotEqual(t, origSignedAt, r.LastSignedAt)
 }
 
+func TestSignRequestWithClockSkew(t *testing.T) {
+	svc := awstesting.NewClient(&service.Config{
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", "SESSION"),
+		Region:      service.String("us-west-2"),
+	})
+	r := svc.NewRequest(
+		&request.Operation{
+			Name:       "BatchGetItem",
+			HTTPMethod: "POST",
+			HTTPPath:   "/",
+		},
+		nil,
+		nil,
+	)
+
+	request.SetClockSkew(svc.ClientInfo.Endpoint, time.Hour)
+	defer request.SetClockSkew(svc.ClientInfo.Endpoint, 0)
+
+	SignSDKRequest(r)
+
+	expectDate := r.Time.Add(time.Hour).UTC().Format(timeFormat)
+	assert.Equal(t, expectDate, r.HTTPRequest.Header.Get("X-Aws-Date"))
+}
+
 func TestSignWithRequestBody(t *testing.T) {
 	creds := credentials.NewStaticCredentials("AKID", "SECRET", "SESSION")
 	signer := NewSigner(creds)
@@ -376,6 +545,68 @@ func TestSignWithRequestBody_Overwrite(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
+func TestLogSigningInfoRedaction(t *testing.T) {
+	var logged []string
+	logger := service.LoggerFunc(func(args ...interface{}) {
+		logged = append(logged, args[0].(string))
+	})
+
+	req, body := buildRequest("dynamodb", "us-east-1", "{}")
+	signer := buildSigner()
+	signer.Debug = service.LogDebugWithSigning
+	signer.Logger = logger
+	signer.Sign(req, body, "dynamodb", "us-east-1", time.Unix(0, 0))
+
+	req, body = buildRequest("dynamodb", "us-east-1", "{}")
+	signer.Presign(req, body, "dynamodb", "us-east-1", 300*time.Second, time.Unix(0, 0))
+
+	if !assert.Len(t, logged, 2) {
+		return
+	}
+	for _, msg := range logged {
+		assert.Contains(t, msg, "DEBUG: Request signature")
+		assert.NotContains(t, msg, "SESSION")
+	}
+
+	assert.Contains(t, logged[0], `x-aws-security-token:REDACTED\n`)
+	assert.Contains(t, logged[1], "X-Aws-Security-Token=REDACTED")
+	assert.Contains(t, logged[1], "X-Aws-Credential=REDACTED")
+	assert.Contains(t, logged[1], "signed_url=")
+	assert.NotContains(t, logged[1], req.URL.Query().Get("X-Aws-Signature"))
+}
+
+func TestRedactCanonicalString(t *testing.T) {
+	redactor := &service.LogRedactor{
+		Headers: []string{"X-Aws-Security-Token"},
+		Query:   []string{"X-Aws-Credential"},
+	}
+
+	canonical := strings.Join([]string{
+		"GET",
+		"/",
+		"Action=List&X-Aws-Credential=AKID%2F19700101",
+		"host:example.com",
+		"x-aws-security-token:SESSION",
+		"",
+		"host;x-aws-security-token",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	expect := strings.Join([]string{
+		"GET",
+		"/",
+		"Action=List&X-Aws-Credential=REDACTED",
+		"host:example.com",
+		"x-aws-security-token:REDACTED",
+		"",
+		"host;x-aws-security-token",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	assert.Equal(t, expect, redactCanonicalString(redactor, canonical))
+	assert.Equal(t, canonical, redactCanonicalString(&service.LogRedactor{}, canonical))
+}
+
 func BenchmarkPresignRequest(b *testing.B) {
 	signer := buildSigner()
 	req, body := buildRequest("dynamodb", "us-east-1", "{}")
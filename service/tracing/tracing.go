@@ -0,0 +1,276 @@
+// Package tracing provides opt-in distributed tracing of service requests,
+// with a Tracer adapting the tracing library of the application.
+//
+//	hooks := tracing.New(myTracer)
+//	hooks.AddHandlers(&svc.Handlers)
+//
+// A span is started for each request sent, with a child span for each attempt
+// to send it. The Build phase of the request is traced as a child span of the
+// request's span, and the Sign, Send and Unmarshal phases as child spans of
+// the attempt's span. The trace headers of the request's span are injected
+// into the HTTP request before it is signed. The headers are the same for
+// each attempt, as a retried request keeps its signature.
+//
+// Presigned requests are not traced.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awserr"
+	"github.com/golib/aws/service/request"
+)
+
+// Attributes set on spans.
+const (
+	AttributeService    = "aws.service"
+	AttributeOperation  = "aws.operation"
+	AttributeRegion     = "aws.region"
+	AttributeRequestID  = "aws.request_id"
+	AttributeErrorCode  = "aws.error_code"
+	AttributeRetryCount = "aws.retry_count"
+	AttributeAttempt    = "aws.attempt"
+	AttributeStatusCode = "http.status_code"
+)
+
+// Names of the spans of a request's attempts and phases. The span of the
+// request is named after its service and operation, e.g. "DynamoDB.GetItem".
+const (
+	SpanAttempt   = "Attempt"
+	SpanBuild     = "Build"
+	SpanSign      = "Sign"
+	SpanSend      = "Send"
+	SpanUnmarshal = "Unmarshal"
+)
+
+// A Span is a traced span of work.
+type Span interface {
+	// SetAttribute sets the attribute of the span.
+	SetAttribute(key string, value interface{})
+
+	// RecordError records the error the work of the span failed with.
+	RecordError(err error)
+
+	// End ends the span.
+	End()
+}
+
+// A Tracer adapts a tracing library for tracing requests.
+type Tracer interface {
+	// Start starts a span with the name, which is a child of the span of
+	// the context, if any. The returned context contains the started span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+
+	// Inject injects the trace headers of the context's span into the
+	// header.
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Hooks trace the requests they're added to with a Tracer.
+type Hooks struct {
+	tracer Tracer
+}
+
+// New returns new Hooks tracing requests with the tracer.
+func New(tracer Tracer) *Hooks {
+	return &Hooks{tracer: tracer}
+}
+
+// AddHandlers injects the tracing handlers into the handlers. Handlers
+// starting spans are pushed to the front of their handler lists, so the
+// spans include the handlers of the list.
+func (h *Hooks) AddHandlers(handlers *request.Handlers) {
+	handlers.Validate.PushFrontNamed(request.NamedHandler{
+		Name: "tracing.StartRequestHandler", Fn: h.startRequest,
+	})
+	handlers.Build.PushFrontNamed(request.NamedHandler{
+		Name: "tracing.StartBuildHandler", Fn: h.startPhase(SpanBuild),
+	})
+	handlers.Build.PushBackNamed(request.NamedHandler{
+		Name: "tracing.EndBuildHandler", Fn: h.endPhase,
+	})
+	handlers.Sign.PushFrontNamed(request.NamedHandler{
+		Name: "tracing.StartAttemptHandler", Fn: h.startAttempt,
+	})
+	handlers.Sign.PushBackNamed(request.NamedHandler{
+		Name: "tracing.EndSignHandler", Fn: h.endPhase,
+	})
+	handlers.Send.PushFrontNamed(request.NamedHandler{
+		Name: "tracing.StartSendHandler", Fn: h.startPhase(SpanSend),
+	})
+	handlers.Send.PushBackNamed(request.NamedHandler{
+		Name: "tracing.EndSendHandler", Fn: h.endPhase,
+	})
+	handlers.UnmarshalMeta.PushFrontNamed(request.NamedHandler{
+		Name: "tracing.StartUnmarshalHandler", Fn: h.startPhase(SpanUnmarshal),
+	})
+	handlers.Unmarshal.PushBackNamed(request.NamedHandler{
+		Name: "tracing.EndUnmarshalHandler", Fn: h.endPhase,
+	})
+	handlers.CompleteAttempt.PushBackNamed(request.NamedHandler{
+		Name: "tracing.EndAttemptHandler", Fn: h.endAttempt,
+	})
+	handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "tracing.EndRequestHandler", Fn: h.endRequest,
+	})
+}
+
+type requestSpansKey struct{}
+
+// requestSpans are the spans of a traced request, kept in its context.
+type requestSpans struct {
+	req *request.Request
+
+	// parent is the context of the request before it was traced.
+	parent context.Context
+
+	ctx  context.Context
+	span Span
+
+	attemptCtx context.Context
+	attempt    Span
+
+	phase Span
+}
+
+// spans returns the spans of the request, or nil if the request is not
+// traced. Copies of the request, such as hedged attempts, are not traced.
+func spans(r *request.Request) *requestSpans {
+	s, ok := r.Context().Value(requestSpansKey{}).(*requestSpans)
+	if !ok || s.req != r {
+		return nil
+	}
+
+	return s
+}
+
+func (h *Hooks) startRequest(r *request.Request) {
+	if r.ExpireTime != 0 || spans(r) != nil {
+		return
+	}
+
+	s := &requestSpans{req: r, parent: r.Context()}
+
+	s.ctx, s.span = h.tracer.Start(s.parent, r.ClientInfo.ServiceName+"."+r.Operation.Name)
+	s.ctx = context.WithValue(s.ctx, requestSpansKey{}, s)
+	h.tracer.Inject(s.ctx, r.HTTPRequest.Header)
+
+	s.span.SetAttribute(AttributeService, r.ClientInfo.ServiceName)
+	s.span.SetAttribute(AttributeOperation, r.Operation.Name)
+	if region := service.StringValue(r.Config.Region); region != "" {
+		s.span.SetAttribute(AttributeRegion, region)
+	}
+
+	r.SetContext(s.ctx)
+}
+
+// startPhase returns a handler starting the span of the phase, as a child of
+// the attempt's span, or of the request's span before the first attempt.
+func (h *Hooks) startPhase(name string) func(*request.Request) {
+	return func(r *request.Request) {
+		s := spans(r)
+		if s == nil {
+			return
+		}
+
+		s.endPhase(r)
+
+		ctx := s.ctx
+		if s.attempt != nil {
+			ctx = s.attemptCtx
+		}
+		_, s.phase = h.tracer.Start(ctx, name)
+	}
+}
+
+func (h *Hooks) endPhase(r *request.Request) {
+	if s := spans(r); s != nil {
+		s.endPhase(r)
+	}
+}
+
+// startAttempt starts the span of an attempt to send the request, and of its
+// Sign phase.
+func (h *Hooks) startAttempt(r *request.Request) {
+	s := spans(r)
+	if s == nil {
+		return
+	}
+
+	// The previous attempt ended without completing if it failed to sign.
+	s.endAttempt(r)
+
+	s.attemptCtx, s.attempt = h.tracer.Start(s.ctx, SpanAttempt)
+	s.attempt.SetAttribute(AttributeAttempt, r.RetryCount+1)
+	r.SetContext(s.attemptCtx)
+
+	_, s.phase = h.tracer.Start(s.attemptCtx, SpanSign)
+}
+
+func (h *Hooks) endAttempt(r *request.Request) {
+	if s := spans(r); s != nil {
+		s.endAttempt(r)
+	}
+}
+
+func (h *Hooks) endRequest(r *request.Request) {
+	s := spans(r)
+	if s == nil {
+		return
+	}
+
+	s.endAttempt(r)
+
+	s.span.SetAttribute(AttributeRetryCount, r.RetryCount)
+	setResultAttributes(s.span, r)
+	s.span.End()
+
+	r.SetContext(s.parent)
+}
+
+// endPhase ends the span of the current phase, if any.
+func (s *requestSpans) endPhase(r *request.Request) {
+	if s.phase == nil {
+		return
+	}
+
+	if r.Error != nil {
+		s.phase.RecordError(r.Error)
+	}
+	s.phase.End()
+	s.phase = nil
+}
+
+// endAttempt ends the span of the current attempt, and its phase, if any.
+func (s *requestSpans) endAttempt(r *request.Request) {
+	s.endPhase(r)
+	if s.attempt == nil {
+		return
+	}
+
+	setResultAttributes(s.attempt, r)
+	s.attempt.End()
+	s.attempt = nil
+
+	r.SetContext(s.ctx)
+}
+
+// setResultAttributes sets the attributes of the request's response and
+// error on the span.
+func setResultAttributes(span Span, r *request.Request) {
+	if r.RequestID != "" {
+		span.SetAttribute(AttributeRequestID, r.RequestID)
+	}
+	if r.HTTPResponse != nil && r.HTTPResponse.StatusCode != 0 {
+		span.SetAttribute(AttributeStatusCode, r.HTTPResponse.StatusCode)
+	}
+
+	if r.Error != nil {
+		if aerr, ok := r.Error.(awserr.Error); ok {
+			span.SetAttribute(AttributeErrorCode, aerr.Code())
+		}
+		span.RecordError(r.Error)
+	}
+}
@@ -0,0 +1,212 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/golib/assert"
+	"github.com/golib/aws/service"
+	"github.com/golib/aws/service/awstesting/mock"
+	"github.com/golib/aws/service/credentials"
+	"github.com/golib/aws/service/request"
+)
+
+type recordedSpan struct {
+	id         int
+	parent     *recordedSpan
+	name       string
+	attributes map[string]interface{}
+	errs       []error
+	ended      bool
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *recordedSpan) End() {
+	s.ended = true
+}
+
+type spanKey struct{}
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	span := &recordedSpan{
+		id:         len(t.spans) + 1,
+		parent:     parent,
+		name:       name,
+		attributes: map[string]interface{}{},
+	}
+	t.spans = append(t.spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *recordingTracer) Inject(ctx context.Context, header http.Header) {
+	if span, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		header.Set("X-Trace-Span", fmt.Sprintf("%d", span.id))
+	}
+}
+
+func (t *recordingTracer) names(parent *recordedSpan) []string {
+	var names []string
+	for _, s := range t.spans {
+		if s.parent == parent {
+			names = append(names, s.name)
+		}
+	}
+
+	return names
+}
+
+func newTracedRequest(server *mock.Server, tracer Tracer, cfgs ...*service.Config) *request.Request {
+	svc := server.NewClient(cfgs...)
+	New(tracer).AddHandlers(&svc.Handlers)
+
+	req := svc.NewRequest(&request.Operation{Name: "ListTables", HTTPMethod: "POST", HTTPPath: "/"}, nil, nil)
+	req.HTTPRequest.Header.Set("X-Aws-Target", "Mock.ListTables")
+	req.Handlers.Retry.PushBack(func(r *request.Request) {
+		r.RetryDelay = 0
+	})
+
+	return req
+}
+
+func TestTracingRetriedRequest(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.Credentials = &credentials.Value{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}
+	server.On("ListTables").Respond(
+		mock.ServerErrorResponse(),
+		mock.JSONResponse(200, `{}`).WithHeader("X-Aws-Request-Id", "abc"),
+	)
+
+	tracer := &recordingTracer{}
+	req := newTracedRequest(server, tracer, service.NewConfig().WithMaxRetries(2))
+
+	type ctxKey struct{}
+	parent := context.WithValue(context.Background(), ctxKey{}, "parent")
+	req.SetContext(parent)
+
+	err := req.Send()
+	assert.Nil(t, err)
+	assert.Equal(t, parent, req.Context())
+
+	root := tracer.spans[0]
+	assert.Nil(t, root.parent)
+	assert.Equal(t, "Mock.ListTables", root.name)
+	assert.Equal(t, []string{"Mock.ListTables"}, tracer.names(nil))
+	assert.Equal(t, []string{SpanBuild, SpanAttempt, SpanAttempt}, tracer.names(root))
+
+	assert.Equal(t, "Mock", root.attributes[AttributeService])
+	assert.Equal(t, "ListTables", root.attributes[AttributeOperation])
+	assert.Equal(t, "mock-region", root.attributes[AttributeRegion])
+	assert.Equal(t, 1, root.attributes[AttributeRetryCount])
+	assert.Equal(t, 200, root.attributes[AttributeStatusCode])
+	assert.Empty(t, root.errs)
+
+	var attempts []*recordedSpan
+	for _, s := range tracer.spans {
+		assert.True(t, s.ended, "span %s not ended", s.name)
+		if s.name == SpanAttempt {
+			attempts = append(attempts, s)
+		}
+	}
+
+	assert.Equal(t, []string{SpanSign, SpanSend, SpanUnmarshal}, tracer.names(attempts[0]))
+	assert.Equal(t, 1, attempts[0].attributes[AttributeAttempt])
+	assert.Equal(t, 500, attempts[0].attributes[AttributeStatusCode])
+	assert.Equal(t, "UnknownError", attempts[0].attributes[AttributeErrorCode])
+	assert.Len(t, attempts[0].errs, 1)
+
+	assert.Equal(t, []string{SpanSign, SpanSend, SpanUnmarshal}, tracer.names(attempts[1]))
+	assert.Equal(t, 2, attempts[1].attributes[AttributeAttempt])
+	assert.Equal(t, 200, attempts[1].attributes[AttributeStatusCode])
+	assert.Empty(t, attempts[1].errs)
+
+	// The trace headers of the request were injected, and signed.
+	reqs := server.Requests()
+	if assert.Len(t, reqs, 2) {
+		for _, r := range reqs {
+			assert.Nil(t, r.SignatureError)
+			assert.Equal(t, fmt.Sprintf("%d", root.id), r.Header.Get("X-Trace-Span"))
+			assert.Contains(t, r.Header.Get("Authorization"), "x-trace-span")
+		}
+	}
+}
+
+func TestTracingFailedRequest(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	server.On("ListTables").Respond(mock.ConnectionResetResponse())
+
+	tracer := &recordingTracer{}
+	req := newTracedRequest(server, tracer, service.NewConfig().WithMaxRetries(0))
+
+	err := req.Send()
+	assert.NotNil(t, err)
+
+	root := tracer.spans[0]
+	assert.Equal(t, []string{SpanBuild, SpanAttempt}, tracer.names(root))
+	assert.Equal(t, "RequestError", root.attributes[AttributeErrorCode])
+	assert.Equal(t, 0, root.attributes[AttributeRetryCount])
+	assert.Len(t, root.errs, 1)
+
+	for _, s := range tracer.spans {
+		assert.True(t, s.ended, "span %s not ended", s.name)
+		if s.name == SpanSend {
+			assert.Len(t, s.errs, 1)
+		}
+	}
+}
+
+func TestTracingValidateError(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	tracer := &recordingTracer{}
+	req := newTracedRequest(server, tracer)
+	req.Handlers.Validate.PushBack(func(r *request.Request) {
+		r.Error = fmt.Errorf("invalid")
+	})
+
+	err := req.Send()
+	assert.NotNil(t, err)
+
+	if assert.Len(t, tracer.spans, 1) {
+		assert.True(t, tracer.spans[0].ended)
+		assert.Len(t, tracer.spans[0].errs, 1)
+	}
+	assert.Empty(t, server.Requests())
+}
+
+func TestTracingPresignNotTraced(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	tracer := &recordingTracer{}
+	req := newTracedRequest(server, tracer)
+
+	_, err := req.Presign(300)
+	assert.Nil(t, err)
+	assert.Empty(t, tracer.spans)
+	assert.Empty(t, req.HTTPRequest.Header.Get("X-Trace-Span"))
+}
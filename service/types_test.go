@@ -1,7 +1,9 @@
 package service
 
 import (
+	"io/ioutil"
 	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/golib/assert"
@@ -73,3 +75,10 @@ func BenchmarkWriteAtBufferParallel(b *testing.B) {
 		}
 	})
 }
+
+func TestIsReaderSeekable(t *testing.T) {
+	assert.True(t, IsReaderSeekable(strings.NewReader("abc")))
+	assert.True(t, IsReaderSeekable(ReadSeekCloser(strings.NewReader("abc"))))
+	assert.False(t, IsReaderSeekable(ReadSeekCloser(ioutil.NopCloser(strings.NewReader("abc")))))
+	assert.False(t, IsReaderSeekable(ioutil.NopCloser(strings.NewReader("abc"))))
+}